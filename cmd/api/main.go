@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
 	"syscall"
 	"time"
 
@@ -17,9 +18,17 @@ import (
 	"url_shortener/internal/service"
 	"url_shortener/pkg/cache"
 	"url_shortener/pkg/database"
+	"url_shortener/pkg/dns"
+	"url_shortener/pkg/events"
+	"url_shortener/pkg/linkcheck"
+	"url_shortener/pkg/metadata"
+	"url_shortener/pkg/safehttp"
+	"url_shortener/pkg/safety"
 	shortener "url_shortener/pkg/shotener"
+	"url_shortener/pkg/unshorten"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 // @title URL Shortener API
@@ -32,6 +41,9 @@ func main() {
 	if err != nil {
 		log.Fatalf("failed to load configuration: %v", err)
 	}
+	if cfg.Auth.JWTSecret == "" {
+		log.Fatalf("JWT_SECRET must be set")
+	}
 
 	// set gin mode
 	if cfg.App.Environment == "production" {
@@ -45,7 +57,32 @@ func main() {
 	}
 
 	// run database migrations
-	if err := db.Migrate(&model.URL{}, &model.URLVisit{}); err != nil {
+	if err := db.Migrate(
+		&model.Workspace{},
+		&model.Membership{},
+		&model.Domain{},
+		&model.URL{},
+		&model.URLVisit{},
+		&model.UTMTemplate{},
+		&model.UsageCounter{},
+		&model.AuditEvent{},
+		&model.WebhookSubscription{},
+		&model.WebhookDelivery{},
+		&model.OutboxEvent{},
+		&model.AbuseReport{},
+		&model.LinkHealth{},
+		&model.PoolKey{},
+	); err != nil {
+		log.Fatalf("failed to run database migrations: %v", err)
+	}
+
+	// short codes used to be unique globally, they are now unique per domain
+	if err := db.DropIndex(&model.URL{}, "idx_urls_short_code"); err != nil {
+		log.Fatalf("failed to run database migrations: %v", err)
+	}
+
+	// template names used to be unique per owner, they are now unique per workspace and owner
+	if err := db.DropIndex(&model.UTMTemplate{}, "idx_utm_templates_owner_name"); err != nil {
 		log.Fatalf("failed to run database migrations: %v", err)
 	}
 
@@ -59,22 +96,235 @@ func main() {
 		log.Fatalf("failed to connect redis: %v", err)
 	}
 
-	// initialize url shortener
-	urlShortener := shortener.NewShortener(cfg.App.URLLength)
+	// short codes are checked against the denied and reserved words, the route
+	// prefixes being always reserved
+	var alphabet string
+	switch cfg.App.CodeAlphabet {
+	case "base62":
+		alphabet = shortener.CharSet
+	case "unambiguous":
+		alphabet = shortener.UnambiguousCharSet
+	default:
+		log.Fatalf("unknown short code alphabet %q", cfg.App.CodeAlphabet)
+	}
+	denylist := shortener.DefaultDenylist
+	if cfg.App.CodeDenylistFile != "" {
+		denylist, err = shortener.LoadWordList(cfg.App.CodeDenylistFile)
+		if err != nil {
+			log.Fatalf("failed to load short code denylist: %v", err)
+		}
+	}
+	reserved := slices.Concat(shortener.DefaultReservedWords, cfg.App.CodeReservedWords)
+	codePolicy := shortener.NewPolicy(alphabet, denylist, reserved)
+
+	// initialize url shortener, short codes being random or encoded numbers of a counter
+	var codeGenerator shortener.CodeGenerator
+	switch cfg.App.CodeStrategy {
+	case "random":
+	case "sequence":
+		var counter shortener.Counter
+		switch cfg.App.CodeCounter {
+		case "postgres":
+			counter, err = shortener.NewSequenceCounter(db.DB, "short_code_seq")
+			if err != nil {
+				log.Fatalf("failed to initialize short code counter: %v", err)
+			}
+		case "redis":
+			counter = shortener.NewBlockCounter(redisClient, "short_code_counter", cfg.App.CodeBlockSize)
+		default:
+			log.Fatalf("unknown short code counter %q", cfg.App.CodeCounter)
+		}
+
+		// scrambling keeps consecutive codes from being guessable
+		var permutation *shortener.Permutation
+		if cfg.App.CodePermutationKey != "" {
+			permutation = shortener.NewPermutation([]byte(cfg.App.CodePermutationKey), shortener.KeyspaceSize(alphabet, cfg.App.URLLength))
+		}
+		codeGenerator = shortener.NewSequenceGenerator(counter, permutation, alphabet, cfg.App.URLLength)
+	default:
+		log.Fatalf("unknown short code strategy %q", cfg.App.CodeStrategy)
+	}
+	urlShortener := shortener.NewShortener(cfg.App.URLLength, codeGenerator, codePolicy)
+	if cfg.App.CodeCheckCharacter {
+		// a check character lets mistyped codes be told apart and corrected
+		urlShortener.EnableCheckCharacter()
+	}
 
-	// initialize repository
+	// initialize repositories
 	urlRepo := repository.NewURLRepository(db.DB)
+	utmRepo := repository.NewUTMTemplateRepository(db.DB)
+	domainRepo := repository.NewDomainRepository(db.DB)
+	workspaceRepo := repository.NewWorkspaceRepository(db.DB)
+	usageRepo := repository.NewUsageRepository(db.DB)
+	auditRepo := repository.NewAuditRepository(db.DB)
+	webhookRepo := repository.NewWebhookRepository(db.DB)
+	outboxRepo := repository.NewOutboxRepository(db.DB)
+	abuseRepo := repository.NewAbuseReportRepository(db.DB)
+	healthRepo := repository.NewLinkHealthRepository(db.DB)
+	keyPoolRepo := repository.NewKeyPoolRepository(db.DB)
+
+	// domain events written to the outbox are relayed to the configured publisher
+	var publisher events.EventPublisher
+	switch cfg.Events.Publisher {
+	case "redis":
+		publisher = events.NewRedisStreamPublisher(redisClient, cfg.Events.Stream, cfg.Events.StreamMaxLen)
+	case "memory":
+		publisher = events.NewMemoryPublisher()
+	default:
+		log.Fatalf("unknown events publisher %q", cfg.Events.Publisher)
+	}
+
+	// destinations are checked against local lists, reloaded when the files change
+	safetyChecker, err := safety.NewChecker(cfg.Safety.BlocklistFile, cfg.Safety.PatternsFile)
+	if err != nil {
+		log.Fatalf("failed to load safety lists: %v", err)
+	}
+	if err := safetyChecker.Watch(); err != nil {
+		log.Fatalf("failed to watch safety lists: %v", err)
+	}
+	defer safetyChecker.Close()
+
+	// destinations are also looked up with a threat-intelligence provider when one is configured
+	var reputation safety.ReputationProvider
+	switch cfg.Reputation.Provider {
+	case "":
+	case "safebrowsing":
+		reputation = safety.NewCachedReputationProvider(
+			safety.NewSafeBrowsingClient(
+				cfg.Reputation.SafeBrowsingURL,
+				cfg.Reputation.SafeBrowsingAPIKey,
+				"url-shortener",
+				&http.Client{Timeout: cfg.Reputation.Timeout},
+			),
+			redisClient,
+			cfg.Reputation.CacheTTL,
+		)
+	default:
+		log.Fatalf("unknown reputation provider %q", cfg.Reputation.Provider)
+	}
+
+	// third-party shortener urls are optionally expanded to the url they hide
+	var expander *unshorten.Expander
+	if cfg.Expander.Enabled {
+		hosts := cfg.Expander.Hosts
+		if len(hosts) == 0 {
+			hosts = unshorten.DefaultHosts
+		}
+		expander = unshorten.NewExpander(hosts, &http.Client{Timeout: cfg.Expander.Timeout}, cfg.Expander.MaxHops)
+	}
+
+	// monthly limits of every plan
+	plans := map[string]model.PlanLimits{
+		model.PlanFree: {
+			Links:     cfg.Quota.FreeLinks,
+			Redirects: cfg.Quota.FreeRedirects,
+			APICalls:  cfg.Quota.FreeAPICalls,
+		},
+		model.PlanPro: {
+			Links:     cfg.Quota.ProLinks,
+			Redirects: cfg.Quota.ProRedirects,
+			APICalls:  cfg.Quota.ProAPICalls,
+		},
+	}
+
+	// initialize services
+	auditService := service.NewAuditService(auditRepo)
+	clickStreamService := service.NewClickStreamService(redisClient)
+	// webhook endpoints are user-supplied, so deliveries only reach public
+	// addresses and redirects are not followed
+	webhookClient := safehttp.NewClient(cfg.Webhook.Timeout)
+	webhookClient.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	webhookService := service.NewWebhookService(
+		webhookRepo,
+		webhookClient,
+		safetyChecker,
+		auditService,
+		cfg.Webhook.MaxAttempts,
+	)
+	domainService := service.NewDomainService(domainRepo, dns.NewNetResolver(), auditService, redisClient)
+	usageService := service.NewUsageService(usageRepo, workspaceRepo, redisClient, plans)
+	metadataService := service.NewMetadataService(
+		urlRepo,
+		metadata.NewFetcher(safehttp.NewClient(cfg.Metadata.Timeout), cfg.Metadata.MaxBytes),
+		redisClient,
+	)
+	safetyService := service.NewSafetyService(safetyChecker, reputation, expander, domainService)
+
+	// generated codes grow longer as collisions get frequent
+	codeLengthService := service.NewCodeLengthService(
+		urlRepo,
+		redisClient,
+		urlShortener,
+		cfg.App.CodeMaxLength,
+		cfg.App.CodeGrowthThreshold,
+		cfg.App.CodeGrowthSamples,
+	)
 
-	// initialize service
+	// codes generated in advance spare link creation the collision checks
+	var keyPoolService service.KeyPoolService
+	if cfg.KeyPool.Enabled {
+		keyPoolService = service.NewKeyPoolService(
+			keyPoolRepo,
+			urlShortener,
+			codeLengthService,
+			cfg.KeyPool.ClaimBatch,
+			cfg.KeyPool.LowWater,
+			cfg.KeyPool.Target,
+		)
+	}
 	urlService := service.NewURLService(
 		urlRepo,
+		utmRepo,
+		healthRepo,
+		domainService,
+		usageService,
+		safetyService,
+		metadataService,
+		auditService,
+		webhookService,
+		clickStreamService,
 		redisClient,
 		urlShortener,
-		cfg.App.ShortURLDomain,
+		keyPoolService,
+		codeLengthService,
+	)
+	utmService := service.NewUTMService(utmRepo, auditService)
+	// committed link changes evict the cached link and notify webhook subscribers
+	outboxService := service.NewOutboxService(outboxRepo, publisher, urlService.HandleLinkEvents, webhookService.HandleLinkEvents)
+	workspaceService := service.NewWorkspaceService(workspaceRepo, auditService)
+	abuseService := service.NewAbuseService(abuseRepo, urlRepo, urlService, auditService)
+	linkHealthService := service.NewLinkHealthService(
+		healthRepo,
+		urlRepo,
+		linkcheck.NewChecker(safehttp.NewClient(cfg.LinkCheck.Timeout)),
+		cfg.LinkCheck.Concurrency,
+		cfg.LinkCheck.HostDelay,
+		cfg.LinkCheck.FailureThreshold,
 	)
 
-	// initialize handler
-	urlHandler := handler.NewURLHandler(urlService)
+	// register the configured short url domain as the default domain
+	if _, err := domainService.EnsureDefaultDomain(context.Background(), cfg.App.ShortURLDomain); err != nil {
+		log.Fatalf("failed to register default domain: %v", err)
+	}
+
+	// page shown in place of disabled links
+	disabledPage, err := handler.NewDisabledPage(cfg.App.DisabledLinkStatus, cfg.App.TakedownStatus, cfg.App.DisabledPageFile)
+	if err != nil {
+		log.Fatalf("failed to load disabled link page: %v", err)
+	}
+
+	// initialize handlers
+	urlHandler := handler.NewURLHandler(urlService, domainService, linkHealthService, disabledPage)
+	utmHandler := handler.NewUTMHandler(utmService)
+	domainHandler := handler.NewDomainHandler(domainService)
+	workspaceHandler := handler.NewWorkspaceHandler(workspaceService)
+	usageHandler := handler.NewUsageHandler(usageService)
+	auditHandler := handler.NewAuditHandler(auditService)
+	webhookHandler := handler.NewWebhookHandler(webhookService)
+	liveHandler := handler.NewLiveHandler(urlService, domainService, clickStreamService)
+	abuseHandler := handler.NewAbuseHandler(abuseService, domainService)
 
 	// create gin router
 	router := gin.New()
@@ -86,8 +336,22 @@ func main() {
 	router.Use(middleware.CORS())
 	router.Use(middleware.Metrics())
 
+	// api routes require an authenticated user, most of them an active workspace
+	api := router.Group("/api", middleware.Auth(cfg.Auth.JWTSecret))
+	workspaceAPI := api.Group("", middleware.Workspace(workspaceService), middleware.Usage(usageService))
+	// abuse moderation and plan changes are left to the service operators, never to the workspace
+	moderationAPI := api.Group("/moderation", middleware.RequireOperator(cfg.Auth.OperatorIDs))
+
 	// register routes
-	urlHandler.RegisterRoutes(router)
+	urlHandler.RegisterRoutes(router, workspaceAPI)
+	utmHandler.RegisterRoutes(workspaceAPI)
+	domainHandler.RegisterRoutes(workspaceAPI)
+	workspaceHandler.RegisterRoutes(api, workspaceAPI)
+	usageHandler.RegisterRoutes(workspaceAPI, moderationAPI)
+	auditHandler.RegisterRoutes(workspaceAPI)
+	webhookHandler.RegisterRoutes(workspaceAPI)
+	liveHandler.RegisterRoutes(workspaceAPI)
+	abuseHandler.RegisterRoutes(router, moderationAPI)
 
 	// add health check endpoint
 	router.GET("/health", func(c *gin.Context) {
@@ -95,13 +359,25 @@ func main() {
 	})
 
 	// add prometheus metrics endpoint
-	// router.GET("/metrics", gin.Wraph(promhttp.Handler()))
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
 	// add swagger documentaion
 	// router.GET("/swagger/*any", ginSwagger.WraphHandler(swaggerFiles.Handler))
 
 	// start periodic tasks
 	go startPeriodicTasks(urlService)
+	go startDomainVerification(domainService, cfg.App.DomainVerifyInterval)
+	go startUsageReconciliation(usageService, cfg.Quota.UsageSyncInterval)
+	go startWebhookDelivery(webhookService, cfg.Webhook.DeliveryInterval)
+	go startOutboxRelay(outboxService, cfg.Events.RelayInterval, cfg.Events.OutboxRetention)
+	go startLinkHealthChecks(linkHealthService, cfg.LinkCheck.Interval)
+	go startCodeLengthSync(codeLengthService, cfg.App.CodeLengthSync)
+	if keyPoolService != nil {
+		go startKeyPoolRefill(keyPoolService, cfg.KeyPool.RefillInterval)
+	}
+	if reputation != nil {
+		go startReputationRescan(urlService, cfg.Reputation.RescanInterval)
+	}
 
 	// create http server
 	server := &http.Server{
@@ -116,7 +392,7 @@ func main() {
 	go func() {
 		log.Printf("starting server on port %s", cfg.Server.Port)
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatal("failed to start server: %v", err)
+			log.Fatalf("failed to start server: %v", err)
 		}
 	}()
 
@@ -165,3 +441,153 @@ func startPeriodicTasks(urlService service.URLService) {
 		cancel()
 	}
 }
+
+// periodically re-verify custom domains, disabling the ones that lost verification
+func startDomainVerification(domainService service.DomainService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		disabled, err := domainService.ReverifyDomains(ctx)
+		if err != nil {
+			log.Printf("error re-verifying domains: %v", err)
+		} else if disabled > 0 {
+			log.Printf("disabled %d domains that lost verification", disabled)
+		}
+
+		cancel()
+	}
+}
+
+// periodically copy the usage counters kept in redis to postgres
+func startUsageReconciliation(usageService service.UsageService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		ctx, cancel := context.WithTimeout(context.Background(), 1*time.Minute)
+		if _, err := usageService.Reconcile(ctx); err != nil {
+			log.Printf("error reconciling usage counters: %v", err)
+		}
+
+		cancel()
+	}
+}
+
+// periodically send the webhook deliveries that are due
+func startWebhookDelivery(webhookService service.WebhookService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		if _, err := webhookService.DeliverDue(ctx); err != nil {
+			log.Printf("error delivering webhooks: %v", err)
+		}
+
+		cancel()
+	}
+}
+
+// periodically look up the reputation of every active link, disabling the malicious ones
+func startReputationRescan(urlService service.URLService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+		disabled, err := urlService.RescanURLs(ctx)
+		if err != nil {
+			log.Printf("error rescanning urls: %v", err)
+		} else if disabled > 0 {
+			log.Printf("disabled %d urls found malicious", disabled)
+		}
+
+		cancel()
+	}
+}
+
+// keep the generated code length in line with the other replicas and report
+// the keyspace utilization, starting at startup
+func startCodeLengthSync(codeLengthService service.CodeLengthService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		if err := codeLengthService.Sync(ctx); err != nil {
+			log.Printf("error syncing short code length: %v", err)
+		}
+		if err := codeLengthService.ReportUtilization(ctx); err != nil {
+			log.Printf("error reporting keyspace utilization: %v", err)
+		}
+
+		cancel()
+		<-ticker.C
+	}
+}
+
+// keep the key pool above its low-water mark, filling it once at startup
+func startKeyPoolRefill(keyPoolService service.KeyPoolService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+		added, err := keyPoolService.Refill(ctx)
+		if err != nil {
+			log.Printf("error refilling key pool: %v", err)
+		} else if added > 0 {
+			log.Printf("added %d codes to the key pool", added)
+		}
+
+		cancel()
+		<-ticker.C
+	}
+}
+
+// periodically health-check the destination of every active link
+func startLinkHealthChecks(linkHealthService service.LinkHealthService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		checked, broken, err := linkHealthService.CheckLinks(ctx)
+		if err != nil {
+			log.Printf("error checking link health: %v", err)
+		} else {
+			log.Printf("checked %d links, %d broken", checked, broken)
+		}
+
+		cancel()
+	}
+}
+
+// continuously publish the outbox events, purging the old published ones once an hour
+func startOutboxRelay(outboxService service.OutboxService, interval, retention time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	purgeTicker := time.NewTicker(1 * time.Hour)
+	defer purgeTicker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Minute)
+			if _, err := outboxService.Relay(ctx); err != nil {
+				log.Printf("error relaying outbox events: %v", err)
+			}
+			cancel()
+		case <-purgeTicker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			if count, err := outboxService.Purge(ctx, retention); err != nil {
+				log.Printf("error purging outbox events: %v", err)
+			} else if count > 0 {
+				log.Printf("purged %d published outbox events", count)
+			}
+			cancel()
+		}
+	}
+}
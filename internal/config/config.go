@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
@@ -11,10 +12,20 @@ import (
 
 // config holds all configuration for the application
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Redis    RedisConfig
-	App      AppConfig
+	Server     ServerConfig
+	Database   DatabaseConfig
+	Redis      RedisConfig
+	App        AppConfig
+	Auth       AuthConfig
+	Quota      QuotaConfig
+	Webhook    WebhookConfig
+	Events     EventsConfig
+	Safety     SafetyConfig
+	Reputation ReputationConfig
+	Expander   ExpanderConfig
+	LinkCheck  LinkCheckConfig
+	Metadata   MetadataConfig
+	KeyPool    KeyPoolConfig
 }
 
 // ServerConfig holds all server related configuration
@@ -45,9 +56,110 @@ type RedisConfig struct {
 
 // AppConfig holds application specific configuration
 type AppConfig struct {
-	ShortURLDomain string
-	URLLength      int
-	Environment    string
+	ShortURLDomain       string
+	URLLength            int
+	CodeStrategy         string
+	CodeCounter          string
+	CodeBlockSize        int64
+	CodePermutationKey   string
+	CodeAlphabet         string
+	CodeDenylistFile     string
+	CodeReservedWords    []string
+	CodeCheckCharacter   bool
+	CodeMaxLength        int
+	CodeGrowthThreshold  float64
+	CodeGrowthSamples    int
+	CodeLengthSync       time.Duration
+	Environment          string
+	DomainVerifyInterval time.Duration
+	DisabledLinkStatus   int
+	TakedownStatus       int
+	DisabledPageFile     string
+}
+
+// AuthConfig holds authentication related configuration
+type AuthConfig struct {
+	JWTSecret string
+	// OperatorIDs are the users operating the service, who moderate the
+	// links of every workspace
+	OperatorIDs []string
+}
+
+// QuotaConfig holds the monthly limits of every plan, zero meaning unlimited
+type QuotaConfig struct {
+	FreeLinks         int64
+	FreeRedirects     int64
+	FreeAPICalls      int64
+	ProLinks          int64
+	ProRedirects      int64
+	ProAPICalls       int64
+	UsageSyncInterval time.Duration
+}
+
+// WebhookConfig holds webhook delivery configuration
+type WebhookConfig struct {
+	DeliveryInterval time.Duration
+	Timeout          time.Duration
+	MaxAttempts      int
+}
+
+// EventsConfig holds domain event publishing configuration
+type EventsConfig struct {
+	Publisher       string
+	Stream          string
+	StreamMaxLen    int64
+	RelayInterval   time.Duration
+	OutboxRetention time.Duration
+}
+
+// SafetyConfig holds the local lists destination URLs are checked against
+type SafetyConfig struct {
+	BlocklistFile string
+	PatternsFile  string
+}
+
+// ReputationConfig holds the threat-intelligence provider configuration, an
+// empty provider disabling reputation lookups
+type ReputationConfig struct {
+	Provider           string
+	SafeBrowsingURL    string
+	SafeBrowsingAPIKey string
+	Timeout            time.Duration
+	CacheTTL           time.Duration
+	RescanInterval     time.Duration
+}
+
+// ExpanderConfig holds the expansion of third-party shortener URLs, empty
+// hosts meaning the well-known shorteners
+type ExpanderConfig struct {
+	Enabled bool
+	Hosts   []string
+	MaxHops int
+	Timeout time.Duration
+}
+
+// LinkCheckConfig holds the destination health checks configuration
+type LinkCheckConfig struct {
+	Interval         time.Duration
+	Timeout          time.Duration
+	Concurrency      int
+	HostDelay        time.Duration
+	FailureThreshold int
+}
+
+// MetadataConfig holds the limits of destination metadata fetches
+type MetadataConfig struct {
+	Timeout  time.Duration
+	MaxBytes int64
+}
+
+// KeyPoolConfig holds the pool of short codes generated in advance
+type KeyPoolConfig struct {
+	Enabled        bool
+	LowWater       int64
+	Target         int64
+	ClaimBatch     int
+	RefillInterval time.Duration
 }
 
 // LoadConfig loads the config from env variable or config file
@@ -78,9 +190,96 @@ func LoadConfig() (*Config, error) {
 		},
 
 		App: AppConfig{
-			ShortURLDomain: getEnv("SHORT_URL_DOMAIN", "http://localhost:8000"),
-			URLLength:      getEnvAsInt("URL_LENGTH", 6),
-			Environment:    getEnv("ENVIRONMENT", "development"),
+			ShortURLDomain:       getEnv("SHORT_URL_DOMAIN", "http://localhost:8000"),
+			URLLength:            getEnvAsInt("URL_LENGTH", 6),
+			CodeStrategy:         getEnv("CODE_STRATEGY", "random"),
+			CodeCounter:          getEnv("CODE_COUNTER", "postgres"),
+			CodeBlockSize:        getEnvAsInt64("CODE_BLOCK_SIZE", 100),
+			CodePermutationKey:   getEnv("CODE_PERMUTATION_KEY", ""),
+			CodeAlphabet:         getEnv("CODE_ALPHABET", "base62"),
+			CodeDenylistFile:     getEnv("CODE_DENYLIST_FILE", ""),
+			CodeReservedWords:    getEnvAsSlice("CODE_RESERVED_WORDS", nil),
+			CodeCheckCharacter:   getEnvAsBool("CODE_CHECK_CHARACTER", false),
+			CodeMaxLength:        getEnvAsInt("CODE_MAX_LENGTH", 12),
+			CodeGrowthThreshold:  getEnvAsFloat("CODE_GROWTH_THRESHOLD", 0.1),
+			CodeGrowthSamples:    getEnvAsInt("CODE_GROWTH_SAMPLES", 500),
+			CodeLengthSync:       getEnvAsDuration("CODE_LENGTH_SYNC_INTERVAL", 1*time.Minute),
+			Environment:          getEnv("ENVIRONMENT", "development"),
+			DomainVerifyInterval: getEnvAsDuration("DOMAIN_VERIFY_INTERVAL", 1*time.Hour),
+			DisabledLinkStatus:   getEnvAsInt("DISABLED_LINK_STATUS", 410),
+			TakedownStatus:       getEnvAsInt("TAKEDOWN_STATUS", 451),
+			DisabledPageFile:     getEnv("DISABLED_PAGE_FILE", ""),
+		},
+
+		Auth: AuthConfig{
+			JWTSecret:   getEnv("JWT_SECRET", ""),
+			OperatorIDs: getEnvAsSlice("OPERATOR_USER_IDS", nil),
+		},
+
+		Quota: QuotaConfig{
+			FreeLinks:         getEnvAsInt64("QUOTA_FREE_LINKS", 1000),
+			FreeRedirects:     getEnvAsInt64("QUOTA_FREE_REDIRECTS", 100000),
+			FreeAPICalls:      getEnvAsInt64("QUOTA_FREE_API_CALLS", 10000),
+			ProLinks:          getEnvAsInt64("QUOTA_PRO_LINKS", 0),
+			ProRedirects:      getEnvAsInt64("QUOTA_PRO_REDIRECTS", 0),
+			ProAPICalls:       getEnvAsInt64("QUOTA_PRO_API_CALLS", 0),
+			UsageSyncInterval: getEnvAsDuration("USAGE_SYNC_INTERVAL", 5*time.Minute),
+		},
+
+		Webhook: WebhookConfig{
+			DeliveryInterval: getEnvAsDuration("WEBHOOK_DELIVERY_INTERVAL", 5*time.Second),
+			Timeout:          getEnvAsDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+			MaxAttempts:      getEnvAsInt("WEBHOOK_MAX_ATTEMPTS", 8),
+		},
+
+		Events: EventsConfig{
+			Publisher:       getEnv("EVENTS_PUBLISHER", "redis"),
+			Stream:          getEnv("EVENTS_STREAM", "events:links"),
+			StreamMaxLen:    getEnvAsInt64("EVENTS_STREAM_MAX_LEN", 100000),
+			RelayInterval:   getEnvAsDuration("EVENTS_RELAY_INTERVAL", 1*time.Second),
+			OutboxRetention: getEnvAsDuration("EVENTS_OUTBOX_RETENTION", 7*24*time.Hour),
+		},
+
+		Safety: SafetyConfig{
+			BlocklistFile: getEnv("SAFETY_BLOCKLIST_FILE", ""),
+			PatternsFile:  getEnv("SAFETY_PATTERNS_FILE", ""),
+		},
+
+		Reputation: ReputationConfig{
+			Provider:           getEnv("REPUTATION_PROVIDER", ""),
+			SafeBrowsingURL:    getEnv("SAFE_BROWSING_URL", "https://safebrowsing.googleapis.com"),
+			SafeBrowsingAPIKey: getEnv("SAFE_BROWSING_API_KEY", ""),
+			Timeout:            getEnvAsDuration("REPUTATION_TIMEOUT", 3*time.Second),
+			CacheTTL:           getEnvAsDuration("REPUTATION_CACHE_TTL", 1*time.Hour),
+			RescanInterval:     getEnvAsDuration("REPUTATION_RESCAN_INTERVAL", 24*time.Hour),
+		},
+
+		Expander: ExpanderConfig{
+			Enabled: getEnvAsBool("EXPAND_SHORTENERS", false),
+			Hosts:   getEnvAsSlice("EXPAND_SHORTENER_HOSTS", nil),
+			MaxHops: getEnvAsInt("EXPAND_MAX_HOPS", 5),
+			Timeout: getEnvAsDuration("EXPAND_TIMEOUT", 5*time.Second),
+		},
+
+		LinkCheck: LinkCheckConfig{
+			Interval:         getEnvAsDuration("LINK_CHECK_INTERVAL", 24*time.Hour),
+			Timeout:          getEnvAsDuration("LINK_CHECK_TIMEOUT", 10*time.Second),
+			Concurrency:      getEnvAsInt("LINK_CHECK_CONCURRENCY", 10),
+			HostDelay:        getEnvAsDuration("LINK_CHECK_HOST_DELAY", 1*time.Second),
+			FailureThreshold: getEnvAsInt("LINK_CHECK_FAILURE_THRESHOLD", 3),
+		},
+
+		Metadata: MetadataConfig{
+			Timeout:  getEnvAsDuration("METADATA_TIMEOUT", 5*time.Second),
+			MaxBytes: getEnvAsInt64("METADATA_MAX_BYTES", 1<<20),
+		},
+
+		KeyPool: KeyPoolConfig{
+			Enabled:        getEnvAsBool("KEY_POOL_ENABLED", false),
+			LowWater:       getEnvAsInt64("KEY_POOL_LOW_WATER", 10000),
+			Target:         getEnvAsInt64("KEY_POOL_TARGET", 50000),
+			ClaimBatch:     getEnvAsInt("KEY_POOL_CLAIM_BATCH", 100),
+			RefillInterval: getEnvAsDuration("KEY_POOL_REFILL_INTERVAL", 30*time.Second),
 		},
 	}
 
@@ -127,6 +326,24 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getEnvAsInt64(key string, defaultValue int64) int64 {
+	valueStr := getEnv(key, fmt.Sprintf("%d", defaultValue))
+	if value, err := strconv.ParseInt(valueStr, 10, 64); err == nil {
+		return value
+	}
+
+	return defaultValue
+}
+
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	valueStr := getEnv(key, strconv.FormatFloat(defaultValue, 'f', -1, 64))
+	if value, err := strconv.ParseFloat(valueStr, 64); err == nil {
+		return value
+	}
+
+	return defaultValue
+}
+
 func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
 	valueStr := getEnv(key, defaultValue.String())
 	if value, err := time.ParseDuration(valueStr); err == nil {
@@ -136,6 +353,31 @@ func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
 	return defaultValue
 }
 
+func getEnvAsBool(key string, defaultValue bool) bool {
+	valueStr := getEnv(key, strconv.FormatBool(defaultValue))
+	if value, err := strconv.ParseBool(valueStr); err == nil {
+		return value
+	}
+
+	return defaultValue
+}
+
+func getEnvAsSlice(key string, defaultValue []string) []string {
+	valueStr := getEnv(key, "")
+	if valueStr == "" {
+		return defaultValue
+	}
+
+	var values []string
+	for _, value := range strings.Split(valueStr, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
+
 // GetDSN returns the PostgreSQL connection string
 func (c *DatabaseConfig) GetDSN() string {
 	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
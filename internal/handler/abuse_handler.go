@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"url_shortener/internal/model"
+	"url_shortener/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handles http request related to abuse reports
+type AbuseHandler struct {
+	abuseService  service.AbuseService
+	domainService service.DomainService
+}
+
+// create a new abuse handler
+func NewAbuseHandler(abuseService service.AbuseService, domainService service.DomainService) *AbuseHandler {
+	return &AbuseHandler{
+		abuseService:  abuseService,
+		domainService: domainService,
+	}
+}
+
+// reportPageData is the data the report page template is rendered with
+type reportPageData struct {
+	ShortCode  string
+	Categories []string
+	Submitted  bool
+	Error      string
+}
+
+// RegisterRoutes registers the public report form on the router and the
+// moderation queue on the operator api group
+func (h *AbuseHandler) RegisterRoutes(router *gin.Engine, moderation *gin.RouterGroup) {
+	router.GET("/report/:shortCode", h.ShowReportForm)
+	router.POST("/report/:shortCode", h.ReportURL)
+	moderation.GET("/abuse-reports", h.ListReports)
+	moderation.POST("/abuse-reports/:id/resolve", h.ResolveReport)
+}
+
+// ShowReportForm renders the form to report a link
+// @Summary Abuse report form
+// @Description Renders the public form to report an abusive short URL
+// @Tags Abuse
+// @Param shortCode path string true "Short URL code"
+// @Produce html
+// @Success 200 {string} string "Report form"
+// @Router /report/{shortCode} [get]
+func (h *AbuseHandler) ShowReportForm(c *gin.Context) {
+	renderHTML(c, http.StatusOK, "report.html", reportPageData{
+		ShortCode:  c.Param("shortCode"),
+		Categories: model.AbuseCategories,
+	})
+}
+
+// ReportURL handles the request to report an abusive link
+// @Summary Report a short URL
+// @Description Flags a short URL on the requested domain as abusive, as a form post or JSON
+// @Tags Abuse
+// @Accept json,x-www-form-urlencoded
+// @Produce json,html
+// @Param shortCode path string true "Short URL code"
+// @Param body body model.CreateAbuseReportRequest true "Report"
+// @Success 201 {object} map[string]string "Report received"
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /report/{shortCode} [post]
+func (h *AbuseHandler) ReportURL(c *gin.Context) {
+	shortCode := c.Param("shortCode")
+	html := c.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) == gin.MIMEHTML
+	page := reportPageData{ShortCode: shortCode, Categories: model.AbuseCategories}
+
+	var req model.CreateAbuseReportRequest
+	if err := c.ShouldBind(&req); err != nil {
+		if html {
+			page.Error = "Please pick a category and check the email address."
+			renderHTML(c, http.StatusBadRequest, "report.html", page)
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid report"})
+		return
+	}
+
+	// Links are reported on the domain the request was made to
+	domain, err := h.domainService.ResolveHost(c.Request.Context(), c.Request.Host)
+	if err == nil {
+		_, err = h.abuseService.Report(c.Request.Context(), domain, shortCode, req, c.ClientIP())
+	}
+	if err != nil {
+		if html {
+			page.Error = "This link does not exist."
+			renderHTML(c, http.StatusNotFound, "report.html", page)
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": "URL not found"})
+		return
+	}
+
+	if html {
+		page.Submitted = true
+		renderHTML(c, http.StatusCreated, "report.html", page)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Report received"})
+}
+
+// ListReports lists the moderation queue of the service
+// @Summary List abuse reports
+// @Description Lists the abuse reports filed against links of every workspace, oldest first. Restricted to service operators
+// @Tags Abuse
+// @Param status query string false "Report status (open or resolved)"
+// @Param limit query int false "Page size, at most 100"
+// @Param offset query int false "Number of reports to skip"
+// @Produce json
+// @Success 200 {object} model.ListAbuseReportsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/moderation/abuse-reports [get]
+func (h *AbuseHandler) ListReports(c *gin.Context) {
+	var req model.ListAbuseReportsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reports, err := h.abuseService.ListReports(c.Request.Context(), req)
+	if errors.Is(err, service.ErrForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, reports)
+}
+
+// ResolveReport handles the moderation decision on a report
+// @Summary Resolve an abuse report
+// @Description Disables, deletes or whitelists the reported link, or dismisses the report, recording the reason. Every open report of the link is resolved. Restricted to service operators
+// @Tags Abuse
+// @Accept json
+// @Produce json
+// @Param id path int true "Abuse report ID"
+// @Param body body model.ResolveAbuseReportRequest true "Decision"
+// @Success 200 {object} model.AbuseReport
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/moderation/abuse-reports/{id}/resolve [post]
+func (h *AbuseHandler) ResolveReport(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid abuse report id"})
+		return
+	}
+
+	var req model.ResolveAbuseReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	report, err := h.abuseService.Resolve(c.Request.Context(), uint(id), req)
+	if errors.Is(err, service.ErrForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
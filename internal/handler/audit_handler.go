@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"net/http"
+
+	"url_shortener/internal/middleware"
+	"url_shortener/internal/model"
+	"url_shortener/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handles http request related to the audit log
+type AuditHandler struct {
+	auditService service.AuditService
+}
+
+// create a new audit handler
+func NewAuditHandler(auditService service.AuditService) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+// RegisterRoutes registers the routes for the audit handler on the workspace api group
+func (h *AuditHandler) RegisterRoutes(api *gin.RouterGroup) {
+	api.GET("/audit-events", middleware.RequireRole(model.RoleAdmin), h.ListEvents)
+	api.GET("/audit-events/export", middleware.RequireRole(model.RoleAdmin), h.ExportEvents)
+}
+
+// ListEvents lists the audit events of the active workspace
+// @Summary List audit events
+// @Description Lists the administrative actions taken in the active workspace, newest first
+// @Tags Audit
+// @Param actor_id query string false "Actor user ID"
+// @Param action query string false "Action, such as link.created"
+// @Param target_type query string false "Target type, such as link"
+// @Param target_id query string false "Target ID"
+// @Param request_id query string false "Request ID"
+// @Param from query string false "RFC 3339 lower bound of the event time"
+// @Param to query string false "RFC 3339 upper bound of the event time"
+// @Param limit query int false "Page size, at most 500"
+// @Param offset query int false "Number of events to skip"
+// @Produce json
+// @Success 200 {object} model.ListAuditEventsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/audit-events [get]
+func (h *AuditHandler) ListEvents(c *gin.Context) {
+	var query model.AuditQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	events, err := h.auditService.Query(c.Request.Context(), query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
+
+// ExportEvents streams the audit events of the active workspace as NDJSON
+// @Summary Export audit events
+// @Description Exports the audit events matching the filters as newline-delimited JSON, oldest first
+// @Tags Audit
+// @Param actor_id query string false "Actor user ID"
+// @Param action query string false "Action, such as link.created"
+// @Param target_type query string false "Target type, such as link"
+// @Param target_id query string false "Target ID"
+// @Param from query string false "RFC 3339 lower bound of the event time"
+// @Param to query string false "RFC 3339 upper bound of the event time"
+// @Produce application/x-ndjson
+// @Success 200
+// @Failure 400 {object} ErrorResponse
+// @Router /api/audit-events/export [get]
+func (h *AuditHandler) ExportEvents(c *gin.Context) {
+	var query model.AuditQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Content-Disposition", `attachment; filename="audit-events.ndjson"`)
+	c.Status(http.StatusOK)
+
+	// the status is already sent, a failure can only cut the stream short
+	if err := h.auditService.Export(c.Request.Context(), query, c.Writer); err != nil {
+		c.Error(err)
+	}
+}
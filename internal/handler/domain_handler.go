@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"url_shortener/internal/middleware"
+	"url_shortener/internal/model"
+	"url_shortener/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handles http request related to short domains
+type DomainHandler struct {
+	domainService service.DomainService
+}
+
+// create a new domain handler
+func NewDomainHandler(domainService service.DomainService) *DomainHandler {
+	return &DomainHandler{
+		domainService: domainService,
+	}
+}
+
+// RegisterRoutes registers the routes for the domain handler on the workspace api group
+func (h *DomainHandler) RegisterRoutes(api *gin.RouterGroup) {
+	api.POST("/domains", middleware.RequireRole(model.RoleAdmin), h.CreateDomain)
+	api.GET("/domains", middleware.RequireRole(model.RoleViewer), h.ListDomains)
+	api.GET("/domains/:id/verification", middleware.RequireRole(model.RoleAdmin), h.GetVerification)
+	api.POST("/domains/:id/verify", middleware.RequireRole(model.RoleAdmin), h.VerifyDomain)
+}
+
+// CreateDomain handles the request to add a short domain
+// @Summary Add a short domain
+// @Description Adds a branded domain short URLs can be served from
+// @Tags Domains
+// @Accept json
+// @Produce json
+// @Param body body model.CreateDomainRequest true "Domain to add"
+// @Success 201 {object} model.Domain
+// @Failure 400 {object} ErrorResponse
+// @Router /api/domains [post]
+func (h *DomainHandler) CreateDomain(c *gin.Context) {
+	var req model.CreateDomainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	domain, err := h.domainService.CreateDomain(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, domain)
+}
+
+// ListDomains lists the short domains
+// @Summary List short domains
+// @Description Lists the shared domains and the domains of the workspace
+// @Tags Domains
+// @Produce json
+// @Success 200 {array} model.Domain
+// @Failure 500 {object} ErrorResponse
+// @Router /api/domains [get]
+func (h *DomainHandler) ListDomains(c *gin.Context) {
+	domains, err := h.domainService.ListDomains(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, domains)
+}
+
+// GetVerification tells how to prove ownership of a domain
+// @Summary Get domain verification record
+// @Description Gets the DNS TXT record proving ownership of a domain
+// @Tags Domains
+// @Param id path int true "Domain ID"
+// @Produce json
+// @Success 200 {object} model.DomainVerificationResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/domains/{id}/verification [get]
+func (h *DomainHandler) GetVerification(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain id"})
+		return
+	}
+
+	verification, err := h.domainService.GetVerification(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, verification)
+}
+
+// VerifyDomain checks the ownership of a domain
+// @Summary Verify a domain
+// @Description Checks the DNS TXT record proving ownership of a domain
+// @Tags Domains
+// @Param id path int true "Domain ID"
+// @Produce json
+// @Success 200 {object} model.Domain
+// @Failure 502 {object} ErrorResponse
+// @Router /api/domains/{id}/verify [post]
+func (h *DomainHandler) VerifyDomain(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain id"})
+		return
+	}
+
+	domain, err := h.domainService.VerifyDomain(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, domain)
+}
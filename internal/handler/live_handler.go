@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"url_shortener/internal/middleware"
+	"url_shortener/internal/model"
+	"url_shortener/internal/requestctx"
+	"url_shortener/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LiveHeartbeatInterval is how often idle click streams send a ping event,
+// keeping proxies from closing them
+const LiveHeartbeatInterval = 15 * time.Second
+
+// handles http request streaming clicks as Server-Sent Events
+type LiveHandler struct {
+	urlService    service.URLService
+	domainService service.DomainService
+	clickStream   service.ClickStreamService
+}
+
+// create a new live click stream handler
+func NewLiveHandler(urlService service.URLService, domainService service.DomainService, clickStream service.ClickStreamService) *LiveHandler {
+	return &LiveHandler{
+		urlService:    urlService,
+		domainService: domainService,
+		clickStream:   clickStream,
+	}
+}
+
+// RegisterRoutes registers the routes for the live handler on the workspace api group
+func (h *LiveHandler) RegisterRoutes(api *gin.RouterGroup) {
+	api.GET("/urls/:shortCode/live", middleware.RequireRole(model.RoleViewer), h.StreamLinkClicks)
+	api.GET("/analytics/live", middleware.RequireRole(model.RoleViewer), h.StreamWorkspaceClicks)
+}
+
+// StreamLinkClicks streams the clicks of a short URL
+// @Summary Stream link clicks
+// @Description Streams the clicks of a short URL of the active workspace as Server-Sent Events named click
+// @Tags Analytics
+// @Param shortCode path string true "Short URL code"
+// @Param domain query string false "Short domain host, the default domain when omitted"
+// @Produce text/event-stream
+// @Success 200 {object} model.ClickEvent
+// @Failure 404 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /api/urls/{shortCode}/live [get]
+func (h *LiveHandler) StreamLinkClicks(c *gin.Context) {
+	domain, err := h.domainService.GetDomain(c.Request.Context(), c.Query("domain"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+		return
+	}
+
+	// links of other workspaces are not found, so watchers only see their own links
+	url, err := h.urlService.FindURL(c.Request.Context(), domain, c.Param("shortCode"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "URL not found"})
+		return
+	}
+
+	h.stream(c, url.WorkspaceID, url.ID)
+}
+
+// StreamWorkspaceClicks streams the clicks of every link of the active workspace
+// @Summary Stream workspace clicks
+// @Description Streams the clicks of every short URL of the active workspace as Server-Sent Events named click
+// @Tags Analytics
+// @Produce text/event-stream
+// @Success 200 {object} model.ClickEvent
+// @Failure 503 {object} ErrorResponse
+// @Router /api/analytics/live [get]
+func (h *LiveHandler) StreamWorkspaceClicks(c *gin.Context) {
+	h.stream(c, requestctx.WorkspaceID(c.Request.Context()), 0)
+}
+
+// stream relays click events to the client until it disconnects
+func (h *LiveHandler) stream(c *gin.Context, workspaceID, linkID uint) {
+	ctx := c.Request.Context()
+
+	clicks, unsubscribe, err := h.clickStream.Subscribe(ctx, workspaceID, linkID)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Click stream unavailable"})
+		return
+	}
+	defer unsubscribe()
+
+	// streams outlive the server write timeout
+	http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+
+	heartbeat := time.NewTicker(LiveHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case click, ok := <-clicks:
+			if !ok {
+				return false
+			}
+			c.SSEvent("click", click)
+		case now := <-heartbeat.C:
+			c.SSEvent("ping", now.Unix())
+		}
+		return true
+	})
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+
+	"url_shortener/internal/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+// templates holds the HTML pages served by the handlers
+var templates = template.Must(template.ParseFS(templateFS, "templates/*.html"))
+
+// renderHTML renders one of the embedded HTML templates
+func renderHTML(c *gin.Context, status int, name string, data interface{}) {
+	c.Render(status, render.HTML{
+		Template: templates,
+		Name:     name,
+		Data:     data,
+	})
+}
+
+// DisabledPage renders the page shown in place of disabled links, with the
+// status of links disabled by their owner or of links taken down
+type DisabledPage struct {
+	status         int
+	takedownStatus int
+	template       *template.Template
+}
+
+// disabledPageData is the data the disabled page template is rendered with
+type disabledPageData struct {
+	ShortCode string `json:"short_code"`
+	TakenDown bool   `json:"taken_down"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// NewDisabledPage creates the disabled link page, rendering the template file
+// when given and the embedded page otherwise
+func NewDisabledPage(status, takedownStatus int, file string) (*DisabledPage, error) {
+	page := &DisabledPage{
+		status:         status,
+		takedownStatus: takedownStatus,
+		template:       templates.Lookup("disabled.html"),
+	}
+
+	if file != "" {
+		tmpl, err := template.ParseFiles(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse disabled page: %w", err)
+		}
+		page.template = tmpl
+	}
+
+	return page, nil
+}
+
+// Render renders the page of a disabled link, or its JSON variant when
+// requested through the Accept header
+func (p *DisabledPage) Render(c *gin.Context, url *model.URL) {
+	status := p.status
+	data := disabledPageData{ShortCode: url.ShortCode}
+	if url.TakenDown() {
+		status = p.takedownStatus
+		data.TakenDown = true
+		data.Reason = url.DisabledReason
+	}
+
+	c.Header("Cache-Control", "no-store")
+	if c.NegotiateFormat(gin.MIMEHTML, gin.MIMEJSON) == gin.MIMEJSON {
+		c.JSON(status, data)
+		return
+	}
+
+	c.Render(status, render.HTML{
+		Template: p.template,
+		Name:     p.template.Name(),
+		Data:     data,
+	})
+}
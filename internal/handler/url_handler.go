@@ -1,43 +1,76 @@
 package handler
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 
+	"url_shortener/internal/middleware"
 	"url_shortener/internal/model"
 	"url_shortener/internal/service"
+	"url_shortener/pkg/qrcode"
+	"url_shortener/pkg/useragent"
 
 	"github.com/gin-gonic/gin"
 )
 
+// PreviewSuffix appended to a short code shows the preview page instead of redirecting
+const PreviewSuffix = "+"
+
+// CountryHeaders are the headers edge proxies put the visitor country in, by priority
+var CountryHeaders = []string{"CF-IPCountry", "CloudFront-Viewer-Country", "X-Country-Code"}
+
 // handles http request relate to urls
 type URLHandler struct {
-	urlService service.URLService
+	urlService    service.URLService
+	domainService service.DomainService
+	healthService service.LinkHealthService
+	disabledPage  *DisabledPage
 }
 
 // create a new url handler
-func NewURLHandler(urlService service.URLService) *URLHandler {
+func NewURLHandler(urlService service.URLService, domainService service.DomainService, healthService service.LinkHealthService, disabledPage *DisabledPage) *URLHandler {
 	return &URLHandler{
-		urlService: urlService,
+		urlService:    urlService,
+		domainService: domainService,
+		healthService: healthService,
+		disabledPage:  disabledPage,
 	}
 }
 
-// RegisterRoutes registers the routes for the URL handler
-func (h *URLHandler) RegisterRoutes(router *gin.Engine) {
-	router.POST("/api/urls", h.CreateShortURL)
-	router.GET("/api/urls/:shortCode/stats", h.GetURLStats)
+// RegisterRoutes registers the routes for the URL handler, management routes
+// on the api group scoped to the active workspace
+func (h *URLHandler) RegisterRoutes(router *gin.Engine, api *gin.RouterGroup) {
+	api.POST("/urls", middleware.RequireRole(model.RoleEditor), h.CreateShortURL)
+	api.GET("/urls", middleware.RequireRole(model.RoleViewer), h.ListURLs)
+	api.PATCH("/urls/:shortCode", middleware.RequireRole(model.RoleEditor), h.UpdateURL)
+	api.DELETE("/urls/:shortCode", middleware.RequireRole(model.RoleEditor), h.DeleteURL)
+	api.GET("/urls/:shortCode/stats", middleware.RequireRole(model.RoleViewer), h.GetURLStats)
+	api.GET("/urls/:shortCode/qr", middleware.RequireRole(model.RoleViewer), h.GetQRCode)
+	api.POST("/urls/:shortCode/metadata/refresh", middleware.RequireRole(model.RoleEditor), h.RefreshMetadata)
+	api.GET("/analytics/campaigns", middleware.RequireRole(model.RoleViewer), h.GetCampaignStats)
+	api.GET("/analytics/broken-links", middleware.RequireRole(model.RoleViewer), h.GetBrokenLinks)
+	api.GET("/codes/suggest", middleware.RequireRole(model.RoleEditor), h.SuggestCodes)
 	router.GET("/:shortCode", h.RedirectToOriginalURL)
 }
 
 // CreateShortURL handles the request to create a short URL
 // @Summary Create a short URL
-// @Description Creates a shortened URL from a long URL
+// @Description Creates a shortened URL from a long URL. With dedupe, the existing link with the same destination and options is returned instead
 // @Tags URLs
 // @Accept json
 // @Produce json
 // @Param body body model.CreateURLRequest true "URL to shorten"
+// @Success 200 {object} model.CreateURLResponse
 // @Success 201 {object} model.CreateURLResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 402 {object} ErrorResponse
+// @Failure 409 {object} model.CodeTakenResponse
+// @Failure 422 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
+// @Failure 502 {object} ErrorResponse
 // @Router /api/urls [post]
 func (h *URLHandler) CreateShortURL(c *gin.Context) {
 	var req model.CreateURLRequest
@@ -51,48 +84,304 @@ func (h *URLHandler) CreateShortURL(c *gin.Context) {
 
 	// Create short URL
 	resp, err := h.urlService.CreateShortURL(c.Request.Context(), req, clientIP)
+	if errors.Is(err, service.ErrInvalidCustomCode) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var taken *service.CodeTakenError
+	if errors.As(err, &taken) {
+		c.JSON(http.StatusConflict, model.CodeTakenResponse{Error: err.Error(), Suggestions: taken.Suggestions})
+		return
+	}
+	if errors.Is(err, service.ErrQuotaExceeded) {
+		c.JSON(http.StatusPaymentRequired, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, service.ErrUnsafeURL) || errors.Is(err, service.ErrRedirectLoop) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, service.ErrExpansionFailed) {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	if resp.Existing {
+		c.JSON(http.StatusOK, resp)
+		return
+	}
+
 	c.JSON(http.StatusCreated, resp)
 }
 
+// ListURLs lists the short URLs of the active workspace
+// @Summary List short URLs
+// @Description Lists the short URLs of the active workspace, newest first
+// @Tags URLs
+// @Param limit query int false "Page size, at most 100"
+// @Param offset query int false "Number of URLs to skip"
+// @Produce json
+// @Success 200 {object} model.ListURLsResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/urls [get]
+func (h *URLHandler) ListURLs(c *gin.Context) {
+	limit, offset := pagination(c)
+
+	urls, err := h.urlService.ListURLs(c.Request.Context(), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, urls)
+}
+
+// UpdateURL handles the request to edit a short URL
+// @Summary Update a short URL
+// @Description Edits the destination, expiry or options of a short URL, or disables it
+// @Tags URLs
+// @Accept json
+// @Produce json
+// @Param shortCode path string true "Short URL code"
+// @Param domain query string false "Short domain host, the default domain when omitted"
+// @Param body body model.UpdateURLRequest true "Fields to change"
+// @Success 200 {object} model.URL
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Failure 502 {object} ErrorResponse
+// @Router /api/urls/{shortCode} [patch]
+func (h *URLHandler) UpdateURL(c *gin.Context) {
+	var req model.UpdateURLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	domain, err := h.domainService.GetDomain(c.Request.Context(), c.Query("domain"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+		return
+	}
+
+	url, err := h.urlService.UpdateURL(c.Request.Context(), domain, c.Param("shortCode"), req)
+	if errors.Is(err, service.ErrForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, service.ErrUnsafeURL) || errors.Is(err, service.ErrRedirectLoop) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, service.ErrExpansionFailed) {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, url)
+}
+
+// DeleteURL handles the request to delete a short URL
+// @Summary Delete a short URL
+// @Description Deletes a short URL
+// @Tags URLs
+// @Param shortCode path string true "Short URL code"
+// @Param domain query string false "Short domain host, the default domain when omitted"
+// @Success 204
+// @Failure 404 {object} ErrorResponse
+// @Router /api/urls/{shortCode} [delete]
+func (h *URLHandler) DeleteURL(c *gin.Context) {
+	domain, err := h.domainService.GetDomain(c.Request.Context(), c.Query("domain"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+		return
+	}
+
+	if err := h.urlService.DeleteURL(c.Request.Context(), domain, c.Param("shortCode")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "URL not found"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// pagination reads the limit and offset query parameters
+func pagination(c *gin.Context) (int, int) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	return limit, offset
+}
+
 // RedirectToOriginalURL redirects a short URL to its original URL
 // @Summary Redirect to original URL
 // @Description Redirects a short URL to its original URL
 // @Tags URLs
-// @Param shortCode path string true "Short URL code"
+// @Param shortCode path string true "Short URL code, suffixed with + for the preview page"
+// @Produce html,json
+// @Success 200 {object} model.URLPreviewResponse "Preview of the destination, or the Open Graph card for link preview bots"
 // @Success 302 {string} string "Redirect to original URL"
-// @Failure 404 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse "Link not found, with the links a mistyped code may have been meant as"
+// @Failure 410 {string} string "Link disabled by its owner"
+// @Failure 451 {string} string "Link taken down"
 // @Failure 500 {object} ErrorResponse
 // @Router /{shortCode} [get]
 func (h *URLHandler) RedirectToOriginalURL(c *gin.Context) {
 	shortCode := c.Param("shortCode")
 
-	// Get original URL
-	originalURL, err := h.urlService.GetOriginalURL(c.Request.Context(), shortCode)
+	// A trailing "+" asks for the preview page instead of the redirect
+	preview := strings.HasSuffix(shortCode, PreviewSuffix)
+	shortCode = strings.TrimSuffix(shortCode, PreviewSuffix)
+
+	// Links are resolved on the domain the request was made to
+	domain, err := h.domainService.ResolveHost(c.Request.Context(), c.Request.Host)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "URL not found or expired"})
 		return
 	}
 
-	// Get URL entity for visit recording
-	url, err := h.urlService.GetURLStats(c.Request.Context(), shortCode)
-	if err == nil {
-		// Record visit in background (don't block the redirect)
-		go h.urlService.RecordVisit(
-			c.Request.Context(),
-			uint(url.VisitCount), // Using visit count as URL ID for simplicity
-			c.ClientIP(),
-			c.Request.UserAgent(),
-			c.Request.Referer(),
-		)
+	// Get URL entity
+	url, err := h.urlService.ResolveURL(c.Request.Context(), domain, shortCode)
+	if err != nil {
+		h.notFound(c, domain, shortCode)
+		return
+	}
+
+	if url.Disabled {
+		h.disabledPage.Render(c, url)
+		return
+	}
+
+	// Link preview bots get the custom preview card instead of the destination's
+	if !url.OpenGraph.IsEmpty() && useragent.IsUnfurler(c.Request.UserAgent()) {
+		h.showUnfurl(c, domain, url)
+		return
+	}
+
+	if preview || url.PreviewMode {
+		h.showPreview(c, url)
+		return
 	}
 
+	// Record visit in background (don't block the redirect)
+	h.recordVisit(c, url)
+
 	// Redirect to original URL
-	c.Redirect(http.StatusFound, originalURL)
+	c.Redirect(http.StatusFound, url.Destination())
+}
+
+// notFound answers a short code with no link, suggesting the links it may have
+// been mistyped from when its check character is wrong
+func (h *URLHandler) notFound(c *gin.Context, domain *model.Domain, shortCode string) {
+	suggestions, err := h.urlService.SuggestCorrections(c.Request.Context(), domain, shortCode)
+	if err != nil || len(suggestions) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "URL not found or expired"})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	if c.NegotiateFormat(gin.MIMEHTML, gin.MIMEJSON) == gin.MIMEJSON {
+		c.JSON(http.StatusNotFound, gin.H{"error": "URL not found or expired", "suggestions": suggestions})
+		return
+	}
+
+	renderHTML(c, http.StatusNotFound, "not_found.html", notFoundPageData{
+		ShortCode:   shortCode,
+		Suggestions: suggestions,
+	})
+}
+
+// notFoundPageData is the data the not found page template is rendered with
+type notFoundPageData struct {
+	ShortCode   string
+	Suggestions []string
+}
+
+// showPreview renders the interstitial page describing the destination of a URL,
+// or its JSON variant when requested through the Accept header
+func (h *URLHandler) showPreview(c *gin.Context, url *model.URL) {
+	preview, err := h.urlService.GetURLPreview(c.Request.Context(), url)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if c.NegotiateFormat(gin.MIMEHTML, gin.MIMEJSON) == gin.MIMEJSON {
+		c.JSON(http.StatusOK, preview)
+		return
+	}
+
+	// Continuing from the page goes straight to the destination, so the page view is the visit
+	h.recordVisit(c, url)
+
+	c.Header("Cache-Control", "no-store")
+	renderHTML(c, http.StatusOK, "preview.html", preview)
+}
+
+// showUnfurl renders the minimal page carrying the Open Graph overrides of a
+// URL. Fetches by preview bots are not visits
+func (h *URLHandler) showUnfurl(c *gin.Context, domain *model.Domain, url *model.URL) {
+	c.Header("Cache-Control", "no-store")
+	renderHTML(c, http.StatusOK, "unfurl.html", unfurlPageData{
+		ShortURL:    domain.BaseURL() + "/" + url.ShortCode,
+		Destination: url.Destination(),
+		Title:       url.OpenGraph.Title,
+		Description: url.OpenGraph.Description,
+		ImageURL:    url.OpenGraph.ImageURL,
+	})
+}
+
+// unfurlPageData is the data the unfurl page template is rendered with
+type unfurlPageData struct {
+	ShortURL    string
+	Destination string
+	Title       string
+	Description string
+	ImageURL    string
+}
+
+// recordVisit records a visit to the URL in background
+func (h *URLHandler) recordVisit(c *gin.Context, url *model.URL) {
+	visit := model.VisitInfo{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Referer:   c.Request.Referer(),
+		Source:    model.VisitSourceLink,
+		Country:   visitorCountry(c),
+	}
+	if c.Query(service.QRSourceParam) == model.VisitSourceQR {
+		visit.Source = model.VisitSourceQR
+	}
+
+	go h.urlService.RecordVisit(context.Background(), url, visit)
+}
+
+// visitorCountry returns the ISO country code of the visitor as geolocated by
+// the CDN or load balancer in front of the service, empty when unknown
+func visitorCountry(c *gin.Context) string {
+	for _, header := range CountryHeaders {
+		if country := strings.ToUpper(strings.TrimSpace(c.GetHeader(header))); len(country) == 2 && country != "XX" {
+			return country
+		}
+	}
+
+	return ""
 }
 
 // GetURLStats gets statistics for a short URL
@@ -100,6 +389,7 @@ func (h *URLHandler) RedirectToOriginalURL(c *gin.Context) {
 // @Description Gets statistics for a short URL
 // @Tags URLs
 // @Param shortCode path string true "Short URL code"
+// @Param domain query string false "Short domain host, the default domain when omitted"
 // @Produce json
 // @Success 200 {object} model.GetURLStatsResponse
 // @Failure 404 {object} ErrorResponse
@@ -108,16 +398,223 @@ func (h *URLHandler) RedirectToOriginalURL(c *gin.Context) {
 func (h *URLHandler) GetURLStats(c *gin.Context) {
 	shortCode := c.Param("shortCode")
 
+	domain, err := h.domainService.GetDomain(c.Request.Context(), c.Query("domain"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+		return
+	}
+
 	// Get URL stats
-	stats, err := h.urlService.GetURLStats(c.Request.Context(), shortCode)
+	stats, err := h.urlService.GetURLStats(c.Request.Context(), domain, shortCode)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "URL not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetQRCode renders a QR code for a short URL
+// @Summary Get QR code
+// @Description Renders a QR code for a short URL as PNG or SVG
+// @Tags URLs
+// @Param shortCode path string true "Short URL code"
+// @Param domain query string false "Short domain host, the default domain when omitted"
+// @Param format query string false "Image format (png or svg)"
+// @Param size query int false "Width and height in pixels"
+// @Param level query string false "Error correction level (L, M, Q or H)"
+// @Param margin query int false "Quiet zone in modules"
+// @Param fg query string false "Foreground hex color"
+// @Param bg query string false "Background hex color"
+// @Produce png,image/svg+xml
+// @Success 200 {file} binary
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/urls/{shortCode}/qr [get]
+func (h *URLHandler) GetQRCode(c *gin.Context) {
+	var req model.QRCodeRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid QR code parameters"})
+		return
+	}
+
+	opts, err := qrOptions(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	format := req.Format
+	if format == "" {
+		format = qrcode.FormatPNG
+	}
+
+	domain, err := h.domainService.GetDomain(c.Request.Context(), c.Query("domain"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+		return
+	}
+
+	url, err := h.urlService.FindURL(c.Request.Context(), domain, c.Param("shortCode"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "URL not found"})
+		return
+	}
+
+	image, err := h.urlService.GetQRCode(c.Request.Context(), url, format, opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.Data(http.StatusOK, qrcode.ContentType(format), image)
+}
+
+// qrOptions converts the QR code query parameters into rendering options
+func qrOptions(req model.QRCodeRequest) (qrcode.Options, error) {
+	opts := qrcode.DefaultOptions()
+
+	if req.Size != 0 {
+		opts.Size = req.Size
+	}
+	if req.Level != "" {
+		opts.Level = req.Level
+	}
+	if req.Margin != nil {
+		opts.Margin = *req.Margin
+	}
+
+	if req.Foreground != "" {
+		fg, err := qrcode.ParseColor(req.Foreground)
+		if err != nil {
+			return opts, err
+		}
+		opts.Foreground = fg
+	}
+	if req.Background != "" {
+		bg, err := qrcode.ParseColor(req.Background)
+		if err != nil {
+			return opts, err
+		}
+		opts.Background = bg
+	}
+
+	return opts, nil
+}
+
+// RefreshMetadata handles the request to fetch the metadata of a destination again
+// @Summary Refresh destination metadata
+// @Description Fetches the title, description, favicon and Open Graph image of the destination of a short URL again
+// @Tags URLs
+// @Param shortCode path string true "Short URL code"
+// @Param domain query string false "Short domain host, the default domain when omitted"
+// @Produce json
+// @Success 200 {object} model.LinkMetadata
+// @Failure 404 {object} ErrorResponse
+// @Failure 502 {object} ErrorResponse
+// @Router /api/urls/{shortCode}/metadata/refresh [post]
+func (h *URLHandler) RefreshMetadata(c *gin.Context) {
+	domain, err := h.domainService.GetDomain(c.Request.Context(), c.Query("domain"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+		return
+	}
+
+	url, err := h.urlService.FindURL(c.Request.Context(), domain, c.Param("shortCode"))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "URL not found"})
 		return
 	}
 
+	metadata, err := h.urlService.RefreshMetadata(c.Request.Context(), url)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, metadata)
+}
+
+// GetCampaignStats gets click statistics grouped by UTM campaign
+// @Summary Get campaign statistics
+// @Description Gets the number of clicks per UTM campaign
+// @Tags Analytics
+// @Produce json
+// @Success 200 {array} model.CampaignStats
+// @Failure 500 {object} ErrorResponse
+// @Router /api/analytics/campaigns [get]
+func (h *URLHandler) GetCampaignStats(c *gin.Context) {
+	stats, err := h.urlService.GetCampaignStats(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
 	c.JSON(http.StatusOK, stats)
 }
 
+// GetBrokenLinks lists the links whose destination stopped working
+// @Summary Get broken links
+// @Description Lists the links of the active workspace whose destination failed several health checks in a row, longest broken first
+// @Tags Analytics
+// @Param limit query int false "Page size, at most 100"
+// @Param offset query int false "Number of links to skip"
+// @Produce json
+// @Success 200 {object} model.ListBrokenLinksResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/analytics/broken-links [get]
+func (h *URLHandler) GetBrokenLinks(c *gin.Context) {
+	limit, offset := pagination(c)
+
+	links, err := h.healthService.ListBroken(c.Request.Context(), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, links)
+}
+
+// SuggestCodes suggests available custom codes close to a requested one
+// @Summary Suggest custom codes
+// @Description Lists available custom codes close to the requested one, such as with the year, a word or a number around it
+// @Tags URLs
+// @Param base query string true "Requested custom code"
+// @Param domain query string false "Domain host, the default domain when empty"
+// @Param limit query int false "Number of suggestions, at most 20"
+// @Produce json
+// @Success 200 {object} model.SuggestCodesResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/codes/suggest [get]
+func (h *URLHandler) SuggestCodes(c *gin.Context) {
+	var req model.SuggestCodesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Limit == 0 {
+		req.Limit = service.DefaultSuggestions
+	}
+
+	domain, err := h.domainService.GetDomain(c.Request.Context(), req.Domain)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+		return
+	}
+
+	suggestions, err := h.urlService.SuggestCodes(c.Request.Context(), domain, req.Base, req.Limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuggestCodesResponse{Base: req.Base, Suggestions: suggestions})
+}
+
 // ErrorResponse represents an error response
 type ErrorResponse struct {
 	Error string `json:"error"`
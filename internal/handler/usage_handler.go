@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"url_shortener/internal/middleware"
+	"url_shortener/internal/model"
+	"url_shortener/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handles http request related to workspace usage
+type UsageHandler struct {
+	usageService service.UsageService
+}
+
+// create a new usage handler
+func NewUsageHandler(usageService service.UsageService) *UsageHandler {
+	return &UsageHandler{
+		usageService: usageService,
+	}
+}
+
+// RegisterRoutes registers the usage of the active workspace on the workspace
+// api group and plan changes on the operator api group
+func (h *UsageHandler) RegisterRoutes(api *gin.RouterGroup, moderation *gin.RouterGroup) {
+	api.GET("/usage", middleware.RequireRole(model.RoleViewer), h.GetUsage)
+	moderation.PUT("/workspaces/:id/plan", h.ChangePlan)
+}
+
+// GetUsage gets the usage of the active workspace
+// @Summary Get workspace usage
+// @Description Gets the usage of the active workspace for the current month along with the limits of its plan
+// @Tags Usage
+// @Produce json
+// @Success 200 {object} model.UsageResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/usage [get]
+func (h *UsageHandler) GetUsage(c *gin.Context) {
+	usage, err := h.usageService.GetUsage(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, usage)
+}
+
+// ChangePlan handles the request to move a workspace to another plan
+// @Summary Change workspace plan
+// @Description Moves a workspace to another configured plan, its new limits applying right away. Restricted to service operators
+// @Tags Usage
+// @Accept json
+// @Produce json
+// @Param id path int true "Workspace ID"
+// @Param body body model.ChangePlanRequest true "Plan"
+// @Success 200 {object} model.Workspace
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/moderation/workspaces/{id}/plan [put]
+func (h *UsageHandler) ChangePlan(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace id"})
+		return
+	}
+
+	var req model.ChangePlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	workspace, err := h.usageService.ChangePlan(c.Request.Context(), uint(id), req.Plan)
+	if errors.Is(err, service.ErrForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, service.ErrUnknownPlan) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, workspace)
+}
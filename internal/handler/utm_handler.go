@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"url_shortener/internal/middleware"
+	"url_shortener/internal/model"
+	"url_shortener/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handles http request related to UTM templates
+type UTMHandler struct {
+	utmService service.UTMService
+}
+
+// create a new UTM handler
+func NewUTMHandler(utmService service.UTMService) *UTMHandler {
+	return &UTMHandler{
+		utmService: utmService,
+	}
+}
+
+// RegisterRoutes registers the routes for the UTM handler on the workspace api group
+func (h *UTMHandler) RegisterRoutes(api *gin.RouterGroup) {
+	api.POST("/utm-templates", middleware.RequireRole(model.RoleEditor), h.CreateTemplate)
+	api.GET("/utm-templates", middleware.RequireRole(model.RoleViewer), h.ListTemplates)
+	api.GET("/utm-templates/:id", middleware.RequireRole(model.RoleViewer), h.GetTemplate)
+	api.DELETE("/utm-templates/:id", middleware.RequireRole(model.RoleEditor), h.DeleteTemplate)
+}
+
+// CreateTemplate handles the request to create a UTM template
+// @Summary Create a UTM template
+// @Description Creates a reusable set of UTM parameters
+// @Tags UTM
+// @Accept json
+// @Produce json
+// @Param body body model.CreateUTMTemplateRequest true "UTM template"
+// @Success 201 {object} model.UTMTemplate
+// @Failure 400 {object} ErrorResponse
+// @Router /api/utm-templates [post]
+func (h *UTMHandler) CreateTemplate(c *gin.Context) {
+	var req model.CreateUTMTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	template, err := h.utmService.CreateTemplate(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, template)
+}
+
+// ListTemplates lists the UTM templates available to the user
+// @Summary List UTM templates
+// @Description Lists the shared UTM templates of the workspace and the personal templates of the user
+// @Tags UTM
+// @Produce json
+// @Success 200 {array} model.UTMTemplate
+// @Failure 500 {object} ErrorResponse
+// @Router /api/utm-templates [get]
+func (h *UTMHandler) ListTemplates(c *gin.Context) {
+	templates, err := h.utmService.ListTemplates(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, templates)
+}
+
+// GetTemplate gets a UTM template
+// @Summary Get a UTM template
+// @Description Gets a UTM template by id
+// @Tags UTM
+// @Param id path int true "Template ID"
+// @Produce json
+// @Success 200 {object} model.UTMTemplate
+// @Failure 404 {object} ErrorResponse
+// @Router /api/utm-templates/{id} [get]
+func (h *UTMHandler) GetTemplate(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template id"})
+		return
+	}
+
+	template, err := h.utmService.GetTemplate(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "UTM template not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// DeleteTemplate deletes a UTM template
+// @Summary Delete a UTM template
+// @Description Deletes a UTM template by id
+// @Tags UTM
+// @Param id path int true "Template ID"
+// @Success 204
+// @Failure 404 {object} ErrorResponse
+// @Router /api/utm-templates/{id} [delete]
+func (h *UTMHandler) DeleteTemplate(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template id"})
+		return
+	}
+
+	if err := h.utmService.DeleteTemplate(c.Request.Context(), uint(id)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "UTM template not found"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"url_shortener/internal/middleware"
+	"url_shortener/internal/model"
+	"url_shortener/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handles http request related to webhooks
+type WebhookHandler struct {
+	webhookService service.WebhookService
+}
+
+// create a new webhook handler
+func NewWebhookHandler(webhookService service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+// RegisterRoutes registers the routes for the webhook handler on the workspace api group
+func (h *WebhookHandler) RegisterRoutes(api *gin.RouterGroup) {
+	api.POST("/webhooks", middleware.RequireRole(model.RoleAdmin), h.CreateWebhook)
+	api.GET("/webhooks", middleware.RequireRole(model.RoleAdmin), h.ListWebhooks)
+	api.DELETE("/webhooks/:id", middleware.RequireRole(model.RoleAdmin), h.DeleteWebhook)
+	api.GET("/webhooks/:id/deliveries", middleware.RequireRole(model.RoleAdmin), h.ListDeliveries)
+	api.POST("/webhooks/:id/deliveries/:deliveryID/replay", middleware.RequireRole(model.RoleAdmin), h.ReplayDelivery)
+}
+
+// CreateWebhook handles the request to subscribe an endpoint to events
+// @Summary Create a webhook
+// @Description Subscribes an endpoint to link events of the active workspace. The signing secret is only returned once
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param body body model.CreateWebhookRequest true "Webhook"
+// @Success 201 {object} model.CreateWebhookResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/webhooks [post]
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var req model.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	webhook, err := h.webhookService.CreateSubscription(c.Request.Context(), req)
+	if errors.Is(err, service.ErrUnsafeURL) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, webhook)
+}
+
+// ListWebhooks lists the webhooks of the active workspace
+// @Summary List webhooks
+// @Description Lists the webhook subscriptions of the active workspace
+// @Tags Webhooks
+// @Produce json
+// @Success 200 {array} model.WebhookSubscription
+// @Failure 500 {object} ErrorResponse
+// @Router /api/webhooks [get]
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	webhooks, err := h.webhookService.ListSubscriptions(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, webhooks)
+}
+
+// DeleteWebhook deletes a webhook
+// @Summary Delete a webhook
+// @Description Deletes a webhook subscription by id
+// @Tags Webhooks
+// @Param id path int true "Webhook ID"
+// @Success 204
+// @Failure 404 {object} ErrorResponse
+// @Router /api/webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook id"})
+		return
+	}
+
+	if err := h.webhookService.DeleteSubscription(c.Request.Context(), uint(id)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListDeliveries lists the delivery log of a webhook
+// @Summary List webhook deliveries
+// @Description Lists the deliveries of a webhook, newest first. Dead deliveries form the dead-letter list
+// @Tags Webhooks
+// @Param id path int true "Webhook ID"
+// @Param status query string false "Delivery status (pending, succeeded or dead)"
+// @Param limit query int false "Page size, at most 100"
+// @Param offset query int false "Number of deliveries to skip"
+// @Produce json
+// @Success 200 {object} model.ListWebhookDeliveriesResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook id"})
+		return
+	}
+
+	var req model.ListWebhookDeliveriesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	deliveries, err := h.webhookService.ListDeliveries(c.Request.Context(), uint(id), req)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// ReplayDelivery handles the request to send a past delivery again
+// @Summary Replay a webhook delivery
+// @Description Queues a new delivery of the payload of a past delivery
+// @Tags Webhooks
+// @Param id path int true "Webhook ID"
+// @Param deliveryID path int true "Delivery ID"
+// @Produce json
+// @Success 202 {object} model.WebhookDelivery
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/webhooks/{id}/deliveries/{deliveryID}/replay [post]
+func (h *WebhookHandler) ReplayDelivery(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook id"})
+		return
+	}
+	deliveryID, err := strconv.ParseUint(c.Param("deliveryID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid delivery id"})
+		return
+	}
+
+	delivery, err := h.webhookService.ReplayDelivery(c.Request.Context(), uint(id), uint(deliveryID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, delivery)
+}
@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"url_shortener/internal/middleware"
+	"url_shortener/internal/model"
+	"url_shortener/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handles http request related to workspaces and their members
+type WorkspaceHandler struct {
+	workspaceService service.WorkspaceService
+}
+
+// create a new workspace handler
+func NewWorkspaceHandler(workspaceService service.WorkspaceService) *WorkspaceHandler {
+	return &WorkspaceHandler{
+		workspaceService: workspaceService,
+	}
+}
+
+// RegisterRoutes registers the routes for the workspace handler, member routes
+// on the api group scoped to the active workspace
+func (h *WorkspaceHandler) RegisterRoutes(api *gin.RouterGroup, workspaceAPI *gin.RouterGroup) {
+	api.POST("/workspaces", h.CreateWorkspace)
+	api.GET("/workspaces", h.ListWorkspaces)
+	workspaceAPI.GET("/workspaces/:workspaceID/members", middleware.RequireRole(model.RoleViewer), h.ListMembers)
+	workspaceAPI.POST("/workspaces/:workspaceID/members", middleware.RequireRole(model.RoleAdmin), h.InviteMember)
+	workspaceAPI.PATCH("/workspaces/:workspaceID/members/:userID", middleware.RequireRole(model.RoleAdmin), h.ChangeMemberRole)
+	workspaceAPI.DELETE("/workspaces/:workspaceID/members/:userID", middleware.RequireRole(model.RoleAdmin), h.RemoveMember)
+}
+
+// CreateWorkspace handles the request to create a workspace
+// @Summary Create a workspace
+// @Description Creates a workspace owned by the authenticated user
+// @Tags Workspaces
+// @Accept json
+// @Produce json
+// @Param body body model.CreateWorkspaceRequest true "Workspace"
+// @Success 201 {object} model.Workspace
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/workspaces [post]
+func (h *WorkspaceHandler) CreateWorkspace(c *gin.Context) {
+	var req model.CreateWorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	workspace, err := h.workspaceService.CreateWorkspace(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, workspace)
+}
+
+// ListWorkspaces lists the workspaces of the authenticated user
+// @Summary List workspaces
+// @Description Lists the workspaces the authenticated user is a member of
+// @Tags Workspaces
+// @Produce json
+// @Success 200 {array} model.Workspace
+// @Failure 500 {object} ErrorResponse
+// @Router /api/workspaces [get]
+func (h *WorkspaceHandler) ListWorkspaces(c *gin.Context) {
+	workspaces, err := h.workspaceService.ListWorkspaces(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, workspaces)
+}
+
+// ListMembers lists the members of a workspace
+// @Summary List workspace members
+// @Description Lists the members of a workspace and their roles
+// @Tags Workspaces
+// @Param workspaceID path int true "Workspace ID"
+// @Produce json
+// @Success 200 {array} model.Membership
+// @Failure 500 {object} ErrorResponse
+// @Router /api/workspaces/{workspaceID}/members [get]
+func (h *WorkspaceHandler) ListMembers(c *gin.Context) {
+	members, err := h.workspaceService.ListMembers(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+// InviteMember handles the request to add a member to a workspace
+// @Summary Invite a workspace member
+// @Description Adds a user to a workspace with the given role
+// @Tags Workspaces
+// @Accept json
+// @Produce json
+// @Param workspaceID path int true "Workspace ID"
+// @Param body body model.InviteMemberRequest true "Member"
+// @Success 201 {object} model.Membership
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/workspaces/{workspaceID}/members [post]
+func (h *WorkspaceHandler) InviteMember(c *gin.Context) {
+	var req model.InviteMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	membership, err := h.workspaceService.InviteMember(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(memberErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, membership)
+}
+
+// ChangeMemberRole handles the request to change the role of a workspace member
+// @Summary Change a member role
+// @Description Changes the role of a workspace member
+// @Tags Workspaces
+// @Accept json
+// @Produce json
+// @Param workspaceID path int true "Workspace ID"
+// @Param userID path string true "User ID"
+// @Param body body model.UpdateMemberRequest true "Role"
+// @Success 200 {object} model.Membership
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/workspaces/{workspaceID}/members/{userID} [patch]
+func (h *WorkspaceHandler) ChangeMemberRole(c *gin.Context) {
+	var req model.UpdateMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	membership, err := h.workspaceService.ChangeMemberRole(c.Request.Context(), c.Param("userID"), req)
+	if err != nil {
+		c.JSON(memberErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, membership)
+}
+
+// RemoveMember handles the request to remove a member from a workspace
+// @Summary Remove a workspace member
+// @Description Removes a user from a workspace
+// @Tags Workspaces
+// @Param workspaceID path int true "Workspace ID"
+// @Param userID path string true "User ID"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/workspaces/{workspaceID}/members/{userID} [delete]
+func (h *WorkspaceHandler) RemoveMember(c *gin.Context) {
+	if err := h.workspaceService.RemoveMember(c.Request.Context(), c.Param("userID")); err != nil {
+		c.JSON(memberErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// memberErrorStatus maps a member management error to its http status
+func memberErrorStatus(err error) int {
+	if errors.Is(err, service.ErrForbidden) {
+		return http.StatusForbidden
+	}
+
+	return http.StatusBadRequest
+}
@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"url_shortener/internal/model"
+	"url_shortener/internal/requestctx"
+	"url_shortener/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// WorkspaceHeader selects the active workspace of requests outside /api/workspaces/:workspaceID
+const WorkspaceHeader = "X-Workspace-ID"
+
+// Auth authenticates requests from the HS256 bearer token in the Authorization
+// header, the token subject being the user ID
+func Auth(secret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || tokenString == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Missing bearer token"})
+			return
+		}
+
+		var claims jwt.RegisteredClaims
+		_, err := jwt.ParseWithClaims(tokenString, &claims, func(token *jwt.Token) (interface{}, error) {
+			return []byte(secret), nil
+		}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+		if err != nil || claims.Subject == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid bearer token"})
+			return
+		}
+
+		c.Set("UserID", claims.Subject)
+		c.Request = c.Request.WithContext(requestctx.WithUserID(c.Request.Context(), claims.Subject))
+
+		c.Next()
+	}
+}
+
+// Workspace resolves the active workspace, from the workspaceID path parameter or
+// the X-Workspace-ID header, and the role of the authenticated user in it
+func Workspace(workspaceService service.WorkspaceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rawID := c.Param("workspaceID")
+		if rawID == "" {
+			rawID = c.GetHeader(WorkspaceHeader)
+		}
+
+		workspaceID, err := strconv.ParseUint(rawID, 10, 64)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Missing or invalid workspace"})
+			return
+		}
+
+		userID := c.GetString("UserID")
+		membership, err := workspaceService.GetMembership(c.Request.Context(), uint(workspaceID), userID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Not a member of this workspace"})
+			return
+		}
+
+		c.Set("WorkspaceID", membership.WorkspaceID)
+		c.Set("Role", membership.Role)
+		c.Request = c.Request.WithContext(requestctx.WithWorkspace(c.Request.Context(), membership.WorkspaceID, membership.Role))
+
+		c.Next()
+	}
+}
+
+// RequireOperator rejects requests whose user is not one of the operators of
+// the service. Operator routes are not scoped to a workspace
+func RequireOperator(operatorIDs []string) gin.HandlerFunc {
+	operators := make(map[string]bool, len(operatorIDs))
+	for _, id := range operatorIDs {
+		operators[id] = true
+	}
+
+	return func(c *gin.Context) {
+		if !operators[c.GetString("UserID")] {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Requires a service operator"})
+			return
+		}
+
+		c.Request = c.Request.WithContext(requestctx.WithOperator(c.Request.Context()))
+		c.Next()
+	}
+}
+
+// RequireRole rejects requests whose user lacks the given role in the active workspace
+func RequireRole(role model.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requestctx.Role(c.Request.Context()).Allows(role) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Requires the " + string(role) + " role"})
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -3,6 +3,8 @@ package middleware
 import (
 	"time"
 
+	"url_shortener/internal/requestctx"
+
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -35,9 +37,6 @@ func init() {
 func Logger() gin.HandlerFunc {
 	return gin.LoggerWithConfig(gin.LoggerConfig{
 		SkipPaths: []string{"/health", "/metrics"},
-		Formatter: func(param gin.LogFormatterParams) string {
-			return gin.LoggerWithConfig(gin.LoggerConfig{})(param)
-		},
 	})
 }
 
@@ -64,8 +63,8 @@ func Metrics() gin.HandlerFunc {
 func CORS() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-Workspace-ID")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -95,6 +94,7 @@ func RequestID() gin.HandlerFunc {
 
 		// Add request ID to context
 		c.Set("RequestID", requestID)
+		c.Request = c.Request.WithContext(requestctx.WithRequest(c.Request.Context(), requestID, c.ClientIP()))
 
 		c.Next()
 	}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"url_shortener/internal/model"
+	"url_shortener/internal/requestctx"
+	"url_shortener/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Usage meters the API calls of the active workspace, rejecting them once the
+// workspace goes over the API call limit of its plan
+func Usage(usageService service.UsageService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		if err := usageService.Meter(ctx, requestctx.WorkspaceID(ctx), model.UsageAPICalls); err != nil {
+			if errors.Is(err, service.ErrQuotaExceeded) {
+				c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Next()
+	}
+}
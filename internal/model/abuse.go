@@ -0,0 +1,80 @@
+package model
+
+import "time"
+
+// Abuse report categories
+const (
+	AbuseCategoryPhishing = "phishing"
+	AbuseCategoryMalware  = "malware"
+	AbuseCategorySpam     = "spam"
+	AbuseCategoryIllegal  = "illegal"
+	AbuseCategoryOther    = "other"
+)
+
+// AbuseCategories lists the categories a report can be filed under
+var AbuseCategories = []string{AbuseCategoryPhishing, AbuseCategoryMalware, AbuseCategorySpam, AbuseCategoryIllegal, AbuseCategoryOther}
+
+// Abuse report statuses
+const (
+	AbuseReportOpen     = "open"
+	AbuseReportResolved = "resolved"
+)
+
+// AbuseAction is the decision taken on a reported link
+type AbuseAction string
+
+// Moderation decisions
+const (
+	AbuseActionDisable   AbuseAction = "disable"
+	AbuseActionDelete    AbuseAction = "delete"
+	AbuseActionWhitelist AbuseAction = "whitelist"
+	AbuseActionDismiss   AbuseAction = "dismiss"
+)
+
+// AbuseReport flags a link as abusive, waiting in the moderation queue of the
+// link's workspace until resolved
+type AbuseReport struct {
+	ID             uint        `gorm:"primaryKey" json:"id"`
+	WorkspaceID    uint        `gorm:"not null;index:idx_abuse_reports_workspace_status,priority:1" json:"workspace_id"`
+	URLID          uint        `gorm:"not null;index" json:"url_id"`
+	DomainID       uint        `gorm:"not null" json:"domain_id"`
+	ShortCode      string      `gorm:"type:varchar(20);not null" json:"short_code"`
+	Category       string      `gorm:"type:varchar(20);not null" json:"category"`
+	Details        string      `gorm:"type:text" json:"details,omitempty"`
+	ReporterEmail  string      `gorm:"type:varchar(255)" json:"reporter_email,omitempty"`
+	ReporterIP     string      `gorm:"type:varchar(45)" json:"reporter_ip,omitempty"`
+	Status         string      `gorm:"type:varchar(20);not null;default:open;index:idx_abuse_reports_workspace_status,priority:2" json:"status"`
+	Action         AbuseAction `gorm:"type:varchar(20)" json:"action,omitempty"`
+	ResolutionNote string      `gorm:"type:text" json:"resolution_note,omitempty"`
+	ResolvedBy     string      `gorm:"type:varchar(64)" json:"resolved_by,omitempty"`
+	ResolvedAt     *time.Time  `json:"resolved_at,omitempty"`
+	CreatedAt      time.Time   `json:"created_at"`
+}
+
+// CreateAbuseReportRequest represents the report form, posted as a form or JSON
+type CreateAbuseReportRequest struct {
+	Category string `form:"category" json:"category" binding:"required,oneof=phishing malware spam illegal other"`
+	Details  string `form:"details" json:"details" binding:"max=2000"`
+	Email    string `form:"email" json:"email" binding:"omitempty,email,max=255"`
+}
+
+// ListAbuseReportsRequest represents the query parameters of the moderation queue
+type ListAbuseReportsRequest struct {
+	Status string `form:"status" binding:"omitempty,oneof=open resolved"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int    `form:"offset" binding:"omitempty,min=0"`
+}
+
+// ListAbuseReportsResponse represents a page of the moderation queue
+type ListAbuseReportsResponse struct {
+	Reports []AbuseReport `json:"reports"`
+	Total   int64         `json:"total"`
+	Limit   int           `json:"limit"`
+	Offset  int           `json:"offset"`
+}
+
+// ResolveAbuseReportRequest represents the moderation decision on a report
+type ResolveAbuseReportRequest struct {
+	Action AbuseAction `json:"action" binding:"required,oneof=disable delete whitelist dismiss"`
+	Reason string      `json:"reason" binding:"required,max=2000"`
+}
@@ -0,0 +1,79 @@
+package model
+
+import "time"
+
+// AuditAction names an administrative action recorded in the audit log
+type AuditAction string
+
+// Audited actions
+const (
+	AuditLinkCreated         AuditAction = "link.created"
+	AuditLinkUpdated         AuditAction = "link.updated"
+	AuditLinkDisabled        AuditAction = "link.disabled"
+	AuditLinkEnabled         AuditAction = "link.enabled"
+	AuditLinkDeleted         AuditAction = "link.deleted"
+	AuditLinkWhitelisted     AuditAction = "link.whitelisted"
+	AuditDomainCreated       AuditAction = "domain.created"
+	AuditDomainVerified      AuditAction = "domain.verified"
+	AuditDomainDisabled      AuditAction = "domain.disabled"
+	AuditUTMTemplateCreated  AuditAction = "utm_template.created"
+	AuditUTMTemplateDeleted  AuditAction = "utm_template.deleted"
+	AuditWorkspaceCreated    AuditAction = "workspace.created"
+	AuditMemberInvited       AuditAction = "member.invited"
+	AuditMemberRoleChanged   AuditAction = "member.role_changed"
+	AuditMemberRemoved       AuditAction = "member.removed"
+	AuditAbuseReportResolved AuditAction = "abuse_report.resolved"
+	AuditWebhookCreated      AuditAction = "webhook.created"
+	AuditWebhookDeleted      AuditAction = "webhook.deleted"
+)
+
+// Audit target types
+const (
+	AuditTargetLink        = "link"
+	AuditTargetDomain      = "domain"
+	AuditTargetUTMTemplate = "utm_template"
+	AuditTargetWorkspace   = "workspace"
+	AuditTargetMember      = "member"
+	AuditTargetAbuseReport = "abuse_report"
+	AuditTargetWebhook     = "webhook"
+)
+
+// AuditActorSystem is the actor of actions taken by background jobs
+const AuditActorSystem = "system"
+
+// AuditEvent is an append-only record of an administrative action. Before and
+// After only hold the fields the action changed
+type AuditEvent struct {
+	ID          uint        `gorm:"primaryKey" json:"id"`
+	WorkspaceID uint        `gorm:"not null;index:idx_audit_events_workspace_created,priority:1" json:"workspace_id"`
+	ActorID     string      `gorm:"type:varchar(64);not null;index" json:"actor_id"`
+	Action      AuditAction `gorm:"type:varchar(50);not null;index" json:"action"`
+	TargetType  string      `gorm:"type:varchar(30);not null" json:"target_type"`
+	TargetID    string      `gorm:"type:varchar(64);not null" json:"target_id"`
+	Before      JSON        `gorm:"type:jsonb" json:"before,omitempty"`
+	After       JSON        `gorm:"type:jsonb" json:"after,omitempty"`
+	IP          string      `gorm:"type:varchar(45)" json:"ip,omitempty"`
+	RequestID   string      `gorm:"type:varchar(64);index" json:"request_id,omitempty"`
+	CreatedAt   time.Time   `gorm:"index:idx_audit_events_workspace_created,priority:2" json:"created_at"`
+}
+
+// AuditQuery represents the query parameters for filtering the audit log
+type AuditQuery struct {
+	ActorID    string      `form:"actor_id"`
+	Action     AuditAction `form:"action"`
+	TargetType string      `form:"target_type"`
+	TargetID   string      `form:"target_id"`
+	RequestID  string      `form:"request_id"`
+	From       *time.Time  `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To         *time.Time  `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Limit      int         `form:"limit" binding:"omitempty,min=1,max=500"`
+	Offset     int         `form:"offset" binding:"omitempty,min=0"`
+}
+
+// ListAuditEventsResponse represents a page of the audit log
+type ListAuditEventsResponse struct {
+	Events []AuditEvent `json:"events"`
+	Total  int64        `json:"total"`
+	Limit  int          `json:"limit"`
+	Offset int          `json:"offset"`
+}
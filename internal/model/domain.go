@@ -0,0 +1,79 @@
+package model
+
+import (
+	"net"
+	"strings"
+	"time"
+)
+
+// Domain verification statuses
+const (
+	DomainStatusPending  = "pending"
+	DomainStatusVerified = "verified"
+	DomainStatusDisabled = "disabled"
+)
+
+// VerificationRecordPrefix is the label of the TXT record proving domain ownership
+const VerificationRecordPrefix = "_shortener-verify"
+
+// Domain represents a short domain links are served from
+type Domain struct {
+	ID                uint       `gorm:"primaryKey" json:"id"`
+	WorkspaceID       *uint      `gorm:"index" json:"workspace_id,omitempty"`
+	Host              string     `gorm:"type:varchar(255);uniqueIndex;not null" json:"host"`
+	Scheme            string     `gorm:"type:varchar(10);not null;default:https" json:"scheme"`
+	IsDefault         bool       `gorm:"default:false" json:"is_default"`
+	Status            string     `gorm:"type:varchar(20);not null;default:pending;index" json:"status"`
+	VerificationToken string     `gorm:"type:varchar(64)" json:"verification_token,omitempty"`
+	VerifiedAt        *time.Time `json:"verified_at,omitempty"`
+	LastCheckedAt     *time.Time `json:"last_checked_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// DomainVerificationResponse tells how to prove ownership of a domain
+type DomainVerificationResponse struct {
+	Domain      string `json:"domain"`
+	Status      string `json:"status"`
+	RecordType  string `json:"record_type"`
+	RecordName  string `json:"record_name"`
+	RecordValue string `json:"record_value"`
+}
+
+// CreateDomainRequest represents the request body for adding a short domain
+type CreateDomainRequest struct {
+	Host   string `json:"host" binding:"required,hostname_port|hostname"`
+	Scheme string `json:"scheme" binding:"omitempty,oneof=http https"`
+}
+
+// BaseURL returns the scheme and host short URLs of the domain start with
+func (d *Domain) BaseURL() string {
+	return d.Scheme + "://" + d.Host
+}
+
+// UsableBy reports whether links of a workspace may use the domain, shared
+// domains having no workspace
+func (d *Domain) UsableBy(workspaceID uint) bool {
+	return d.WorkspaceID == nil || *d.WorkspaceID == workspaceID
+}
+
+// IsVerified reports whether links may be created on and served from the domain
+func (d *Domain) IsVerified() bool {
+	return d.Status == DomainStatusVerified
+}
+
+// VerificationRecordName returns the DNS name of the ownership TXT record
+func (d *Domain) VerificationRecordName() string {
+	hostname := d.Host
+	if host, _, err := net.SplitHostPort(d.Host); err == nil {
+		hostname = host
+	}
+
+	return VerificationRecordPrefix + "." + hostname
+}
+
+// NormalizeHost lowercases a host and strips its trailing dot so that
+// Host headers and stored domains compare equal
+func NormalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+}
@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// PoolKey is a short code generated in advance. Claimed keys are kept so the
+// same code is never generated into the pool twice
+type PoolKey struct {
+	Code      string     `gorm:"primaryKey;type:varchar(20)" json:"code"`
+	ClaimedAt *time.Time `gorm:"index" json:"claimed_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TableName names the pool table after what it holds
+func (PoolKey) TableName() string {
+	return "key_pool"
+}
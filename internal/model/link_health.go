@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// LinkHealth is the outcome of the latest health check of the destination of a
+// link. A link is broken once its destination failed several checks in a row
+type LinkHealth struct {
+	ID                  uint       `gorm:"primaryKey" json:"-"`
+	URLID               uint       `gorm:"not null;uniqueIndex" json:"url_id"`
+	URL                 *URL       `gorm:"foreignKey:URLID" json:"url,omitempty"`
+	WorkspaceID         uint       `gorm:"not null;index:idx_link_health_workspace_broken,priority:1" json:"workspace_id"`
+	StatusCode          int        `json:"status_code,omitempty"`
+	LatencyMs           int64      `json:"latency_ms"`
+	FinalURL            string     `gorm:"type:text" json:"final_url,omitempty"`
+	LastError           string     `gorm:"type:text" json:"last_error,omitempty"`
+	ConsecutiveFailures int        `gorm:"not null;default:0" json:"consecutive_failures"`
+	Broken              bool       `gorm:"not null;default:false;index:idx_link_health_workspace_broken,priority:2" json:"broken"`
+	CheckedAt           time.Time  `json:"checked_at"`
+	BrokenSince         *time.Time `json:"broken_since,omitempty"`
+}
+
+// TableName keeps the health of links in a single row per link
+func (LinkHealth) TableName() string {
+	return "link_health"
+}
+
+// ListBrokenLinksResponse represents a page of the broken links report
+type ListBrokenLinksResponse struct {
+	Links  []LinkHealth `json:"links"`
+	Total  int64        `json:"total"`
+	Limit  int          `json:"limit"`
+	Offset int          `json:"offset"`
+}
@@ -0,0 +1,50 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Domain event types written to the outbox
+const (
+	EventLinkCreated = "link.created"
+	EventLinkUpdated = "link.updated"
+	EventLinkDeleted = "link.deleted"
+	EventLinkExpired = "link.expired"
+)
+
+// AggregateLink is the aggregate type of link events
+const AggregateLink = "link"
+
+// OutboxEvent is a domain event written in the same transaction as the change
+// it describes, then published by the outbox relay
+type OutboxEvent struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	WorkspaceID   uint       `gorm:"not null;default:0" json:"workspace_id"`
+	AggregateType string     `gorm:"type:varchar(30);not null" json:"aggregate_type"`
+	AggregateID   string     `gorm:"type:varchar(64);not null" json:"aggregate_id"`
+	EventType     string     `gorm:"type:varchar(50);not null" json:"event_type"`
+	Payload       JSON       `gorm:"type:jsonb;not null" json:"payload"`
+	PublishedAt   *time.Time `gorm:"index" json:"published_at,omitempty"`
+	Attempts      int        `gorm:"not null;default:0" json:"attempts"`
+	LastError     string     `gorm:"type:text" json:"last_error,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// NewLinkEvent returns the outbox event of a change to a link, carrying the link as payload
+func NewLinkEvent(eventType string, url *URL) (*OutboxEvent, error) {
+	payload, err := json.Marshal(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s event: %w", eventType, err)
+	}
+
+	return &OutboxEvent{
+		WorkspaceID:   url.WorkspaceID,
+		AggregateType: AggregateLink,
+		AggregateID:   strconv.FormatUint(uint64(url.ID), 10),
+		EventType:     eventType,
+		Payload:       payload,
+	}, nil
+}
@@ -8,33 +8,138 @@ import (
 
 // URL represents a shortened URL in the system
 type URL struct {
-	ID          uint           `gorm:"primaryKey" json:"id"`
-	OriginalURL string         `gorm:"type:text;not null" json:"original_url"`
-	ShortCode   string         `gorm:"type:varchar(20);uniqueIndex;not null" json:"short_code"`
-	VisitCount  int64          `gorm:"default:0" json:"visit_count"`
-	ExpiresAt   *time.Time     `json:"expires_at"`
-	CreatedByIP string         `gorm:"type:varchar(45)" json:"created_by_ip"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+	ID              uint           `gorm:"primaryKey" json:"id"`
+	WorkspaceID     uint           `gorm:"index;index:idx_urls_workspace_destination,priority:1" json:"workspace_id"`
+	OriginalURL     string         `gorm:"type:text;not null" json:"original_url"`
+	DestinationHash string         `gorm:"type:char(64);not null;default:'';index:idx_urls_workspace_destination,priority:2" json:"-"`
+	DomainID        uint           `gorm:"not null;default:0;uniqueIndex:idx_urls_domain_short_code,priority:1" json:"domain_id"`
+	ShortCode       string         `gorm:"type:varchar(20);not null;uniqueIndex:idx_urls_domain_short_code,priority:2" json:"short_code"`
+	VisitCount      int64          `gorm:"default:0" json:"visit_count"`
+	ExpiresAt       *time.Time     `json:"expires_at"`
+	CreatedByIP     string         `gorm:"type:varchar(45)" json:"created_by_ip"`
+	UTM             UTMParams      `gorm:"embedded;embeddedPrefix:utm_" json:"utm"`
+	UTMOnRedirect   bool           `gorm:"default:false" json:"utm_on_redirect"`
+	PreviewMode     bool           `gorm:"default:false" json:"preview_mode"`
+	Disabled        bool           `gorm:"default:false" json:"disabled"`
+	DisabledBy      string         `gorm:"type:varchar(20);not null;default:''" json:"disabled_by,omitempty"`
+	DisabledReason  string         `gorm:"type:text;not null;default:''" json:"disabled_reason,omitempty"`
+	Whitelisted     bool           `gorm:"default:false" json:"whitelisted"`
+	Metadata        LinkMetadata   `gorm:"embedded;embeddedPrefix:meta_" json:"metadata"`
+	OpenGraph       OpenGraph      `gorm:"embedded;embeddedPrefix:og_" json:"open_graph"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// LinkMetadata describes the destination page of a link, fetched in the
+// background after the link is created or its destination changes
+type LinkMetadata struct {
+	Title       string     `gorm:"type:varchar(500)" json:"title,omitempty"`
+	Description string     `gorm:"type:text" json:"description,omitempty"`
+	FaviconURL  string     `gorm:"type:text" json:"favicon_url,omitempty"`
+	ImageURL    string     `gorm:"type:text" json:"image_url,omitempty"`
+	FetchError  string     `gorm:"type:text" json:"fetch_error,omitempty"`
+	FetchedAt   *time.Time `json:"fetched_at,omitempty"`
+}
+
+// OpenGraph overrides the preview card shown when a link is shared in chat and
+// social apps
+type OpenGraph struct {
+	Title       string `gorm:"type:varchar(300)" json:"title,omitempty" binding:"max=300"`
+	Description string `gorm:"type:text" json:"description,omitempty" binding:"max=1000"`
+	ImageURL    string `gorm:"type:text" json:"image_url,omitempty" binding:"omitempty,url,startswith=http"`
+}
+
+// IsEmpty reports whether no preview card field is overridden
+func (o OpenGraph) IsEmpty() bool {
+	return o.Title == "" && o.Description == "" && o.ImageURL == ""
+}
+
+// Who disabled a link. Links taken down by moderation or reputation checks
+// cannot be enabled again by their owner
+const (
+	DisabledByOwner      = "owner"
+	DisabledByModeration = "moderation"
+	DisabledByReputation = "reputation"
+)
+
+// TakenDown reports whether the link was disabled by someone other than its owner
+func (u *URL) TakenDown() bool {
+	return u.Disabled && u.DisabledBy != "" && u.DisabledBy != DisabledByOwner
 }
 
 // URLVisit tracks each visit to a shortened URL
 type URLVisit struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
-	URLID     uint      `gorm:"not null" json:"url_id"`
-	URL       URL       `gorm:"foreignKey:URLID" json:"-"`
-	IP        string    `gorm:"type:varchar(45)" json:"ip"`
-	UserAgent string    `gorm:"type:text" json:"user_agent"`
-	Referer   string    `gorm:"type:text" json:"referer"`
-	CreatedAt time.Time `json:"created_at"`
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	URLID       uint      `gorm:"not null" json:"url_id"`
+	URL         URL       `gorm:"foreignKey:URLID" json:"-"`
+	IP          string    `gorm:"type:varchar(45)" json:"ip"`
+	UserAgent   string    `gorm:"type:text" json:"user_agent"`
+	Referer     string    `gorm:"type:text" json:"referer"`
+	UTMCampaign string    `gorm:"type:varchar(255);index" json:"utm_campaign"`
+	Source      string    `gorm:"type:varchar(20);default:link;index" json:"source"`
+	CreatedAt   time.Time `json:"created_at"`
 }
 
+// Visit sources, telling how the visitor reached a short URL
+const (
+	VisitSourceLink = "link"
+	VisitSourceQR   = "qr"
+)
+
 // CreateURLRequest represents the request body for creating a short URL
 type CreateURLRequest struct {
-	OriginalURL string     `json:"original_url" binding:"required,url"`
-	ExpiresAt   *time.Time `json:"expires_at"`
-	CustomCode  string     `json:"custom_code"`
+	OriginalURL   string     `json:"original_url" binding:"required,url"`
+	ExpiresAt     *time.Time `json:"expires_at"`
+	CustomCode    string     `json:"custom_code"`
+	Domain        string     `json:"domain"`
+	UTMTemplateID *uint      `json:"utm_template_id"`
+	UTM           *UTMParams `json:"utm"`
+	UTMOnRedirect bool       `json:"utm_on_redirect"`
+	PreviewMode   bool       `json:"preview_mode"`
+	OpenGraph     *OpenGraph `json:"open_graph"`
+	// Dedupe returns the active link of the workspace with the same
+	// destination and options instead of creating a new one
+	Dedupe bool `json:"dedupe"`
+}
+
+// ListURLsResponse represents a page of the links of a workspace
+type ListURLsResponse struct {
+	URLs   []URL `json:"urls"`
+	Total  int64 `json:"total"`
+	Limit  int   `json:"limit"`
+	Offset int   `json:"offset"`
+}
+
+// VisitInfo describes an incoming visit to a shortened URL
+type VisitInfo struct {
+	IP        string
+	UserAgent string
+	Referer   string
+	Source    string
+	Country   string
+}
+
+// ClickEvent is a visit streamed live to the clients watching a link or workspace
+type ClickEvent struct {
+	LinkID      uint      `json:"link_id"`
+	ShortCode   string    `json:"short_code"`
+	Timestamp   time.Time `json:"timestamp"`
+	Country     string    `json:"country,omitempty"`
+	RefererHost string    `json:"referer_host,omitempty"`
+	Device      string    `json:"device"`
+	Source      string    `json:"source"`
+}
+
+// UpdateURLRequest represents the request body for editing a short URL, nil
+// fields being left unchanged
+type UpdateURLRequest struct {
+	OriginalURL   *string    `json:"original_url" binding:"omitempty,url"`
+	ExpiresAt     *time.Time `json:"expires_at"`
+	UTMOnRedirect *bool      `json:"utm_on_redirect"`
+	PreviewMode   *bool      `json:"preview_mode"`
+	Disabled      *bool      `json:"disabled"`
+	OpenGraph     *OpenGraph `json:"open_graph"`
 }
 
 // CreateURLResponse represents the response body after creating a short URL
@@ -44,13 +149,68 @@ type CreateURLResponse struct {
 	ShortCode   string     `json:"short_code"`
 	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
 	CreatedAt   time.Time  `json:"created_at"`
+	Existing    bool       `json:"existing,omitempty"`
+}
+
+// SuggestCodesRequest represents the query of a custom code suggestion request
+type SuggestCodesRequest struct {
+	Base   string `form:"base" binding:"required,max=20"`
+	Domain string `form:"domain"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=20"`
+}
+
+// SuggestCodesResponse lists available custom codes close to a requested one
+type SuggestCodesResponse struct {
+	Base        string   `json:"base"`
+	Suggestions []string `json:"suggestions"`
+}
+
+// CodeTakenResponse represents the error returned for a custom code already in use
+type CodeTakenResponse struct {
+	Error       string   `json:"error"`
+	Suggestions []string `json:"suggestions"`
 }
 
 // GetURLStatsResponse represents the URL statistics response
 type GetURLStatsResponse struct {
-	ShortURL    string     `json:"short_url"`
-	OriginalURL string     `json:"original_url"`
-	VisitCount  int64      `json:"visit_count"`
-	CreatedAt   time.Time  `json:"created_at"`
-	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	ShortURL    string      `json:"short_url"`
+	OriginalURL string      `json:"original_url"`
+	VisitCount  int64       `json:"visit_count"`
+	CreatedAt   time.Time   `json:"created_at"`
+	ExpiresAt   *time.Time  `json:"expires_at,omitempty"`
+	UTM         *UTMParams  `json:"utm,omitempty"`
+	Health      *LinkHealth `json:"health,omitempty"`
+}
+
+// URLPreviewResponse describes where a short URL leads, shown before redirecting
+type URLPreviewResponse struct {
+	ShortURL    string    `json:"short_url"`
+	Destination string    `json:"destination"`
+	Domain      string    `json:"domain"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// QRCodeRequest represents the query parameters for rendering a QR code
+type QRCodeRequest struct {
+	Format     string `form:"format" binding:"omitempty,oneof=png svg"`
+	Size       int    `form:"size" binding:"omitempty,min=64,max=2048"`
+	Level      string `form:"level" binding:"omitempty,oneof=L M Q H"`
+	Margin     *int   `form:"margin" binding:"omitempty,min=0,max=16"`
+	Foreground string `form:"fg"`
+	Background string `form:"bg"`
+}
+
+// Destination returns the URL visitors are redirected to, applying the
+// UTM parameters when they are merged at redirect time
+func (u *URL) Destination() string {
+	if !u.UTMOnRedirect || u.UTM.IsEmpty() {
+		return u.OriginalURL
+	}
+
+	destination, err := u.UTM.Apply(u.OriginalURL)
+	if err != nil {
+		return u.OriginalURL
+	}
+
+	return destination
 }
@@ -0,0 +1,100 @@
+package model
+
+import "time"
+
+// UsageMetric is a usage counter metered per workspace and month
+type UsageMetric string
+
+// Metered usage
+const (
+	UsageLinks     UsageMetric = "links"
+	UsageRedirects UsageMetric = "redirects"
+	UsageAPICalls  UsageMetric = "api_calls"
+)
+
+// UsageMetrics lists every metered usage
+var UsageMetrics = []UsageMetric{UsageLinks, UsageRedirects, UsageAPICalls}
+
+// Workspace plans
+const (
+	PlanFree = "free"
+	PlanPro  = "pro"
+)
+
+// UsagePeriodLayout formats the monthly usage period of a time
+const UsagePeriodLayout = "2006-01"
+
+// Usage holds the value of every usage metric
+type Usage struct {
+	Links     int64 `gorm:"not null;default:0" json:"links"`
+	Redirects int64 `gorm:"not null;default:0" json:"redirects"`
+	APICalls  int64 `gorm:"not null;default:0" json:"api_calls"`
+}
+
+// PlanLimits holds the monthly limits of a plan, zero meaning unlimited
+type PlanLimits Usage
+
+// UsageCounter is the usage of a workspace during a month, reconciled from Redis
+type UsageCounter struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	WorkspaceID uint      `gorm:"not null;uniqueIndex:idx_usage_counters_workspace_period" json:"workspace_id"`
+	Period      string    `gorm:"type:varchar(7);not null;uniqueIndex:idx_usage_counters_workspace_period" json:"period"`
+	Usage       Usage     `gorm:"embedded" json:"usage"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// UsageResponse represents the response body of the usage of a workspace
+type UsageResponse struct {
+	WorkspaceID uint       `json:"workspace_id"`
+	Plan        string     `json:"plan"`
+	Period      string     `json:"period"`
+	Usage       Usage      `json:"usage"`
+	Limits      PlanLimits `json:"limits"`
+}
+
+// UsagePeriod returns the usage period t falls in
+func UsagePeriod(t time.Time) string {
+	return t.UTC().Format(UsagePeriodLayout)
+}
+
+// Get returns the value of a metric
+func (u Usage) Get(metric UsageMetric) int64 {
+	switch metric {
+	case UsageLinks:
+		return u.Links
+	case UsageRedirects:
+		return u.Redirects
+	case UsageAPICalls:
+		return u.APICalls
+	}
+
+	return 0
+}
+
+// Set sets the value of a metric
+func (u *Usage) Set(metric UsageMetric, value int64) {
+	switch metric {
+	case UsageLinks:
+		u.Links = value
+	case UsageRedirects:
+		u.Redirects = value
+	case UsageAPICalls:
+		u.APICalls = value
+	}
+}
+
+// Limit returns the limit of a metric, zero meaning unlimited
+func (l PlanLimits) Limit(metric UsageMetric) int64 {
+	return Usage(l).Get(metric)
+}
+
+// Exceeded reports whether a usage value goes over the limit of a metric
+func (l PlanLimits) Exceeded(metric UsageMetric, value int64) bool {
+	limit := l.Limit(metric)
+	return limit > 0 && value > limit
+}
+
+// ChangePlanRequest represents the request to move a workspace to another plan
+type ChangePlanRequest struct {
+	Plan string `json:"plan" binding:"required,max=20"`
+}
@@ -0,0 +1,98 @@
+package model
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// UTMParams holds the UTM tracking parameters of a link
+type UTMParams struct {
+	Source   string `gorm:"type:varchar(255)" json:"source,omitempty"`
+	Medium   string `gorm:"type:varchar(255)" json:"medium,omitempty"`
+	Campaign string `gorm:"type:varchar(255)" json:"campaign,omitempty"`
+	Term     string `gorm:"type:varchar(255)" json:"term,omitempty"`
+	Content  string `gorm:"type:varchar(255)" json:"content,omitempty"`
+}
+
+// UTMTemplate is a reusable set of UTM parameters owned by a workspace, or by
+// a single user of the workspace for personal templates
+type UTMTemplate struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	WorkspaceID uint      `gorm:"not null;default:0;uniqueIndex:idx_utm_templates_workspace_owner_name,priority:1" json:"workspace_id"`
+	OwnerID     string    `gorm:"type:varchar(64);not null;default:'';uniqueIndex:idx_utm_templates_workspace_owner_name,priority:2" json:"owner_id,omitempty"`
+	Name        string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_utm_templates_workspace_owner_name,priority:3" json:"name"`
+	UTM         UTMParams `gorm:"embedded;embeddedPrefix:utm_" json:"utm"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// CreateUTMTemplateRequest represents the request body for creating a UTM template
+type CreateUTMTemplateRequest struct {
+	Name     string    `json:"name" binding:"required"`
+	Personal bool      `json:"personal"`
+	UTM      UTMParams `json:"utm"`
+}
+
+// CampaignStats represents the clicks attributed to a single UTM campaign
+type CampaignStats struct {
+	Campaign string `json:"campaign"`
+	Clicks   int64  `json:"clicks"`
+	Links    int64  `json:"links"`
+}
+
+// AccessibleBy reports whether a user of a workspace may use the template
+func (t *UTMTemplate) AccessibleBy(workspaceID uint, userID string) bool {
+	return t.WorkspaceID == workspaceID && (t.OwnerID == "" || t.OwnerID == userID)
+}
+
+// IsEmpty reports whether no UTM parameter is set
+func (p UTMParams) IsEmpty() bool {
+	return p == UTMParams{}
+}
+
+// Merge returns a copy of p where every non-empty field of other takes precedence
+func (p UTMParams) Merge(other UTMParams) UTMParams {
+	if other.Source != "" {
+		p.Source = other.Source
+	}
+	if other.Medium != "" {
+		p.Medium = other.Medium
+	}
+	if other.Campaign != "" {
+		p.Campaign = other.Campaign
+	}
+	if other.Term != "" {
+		p.Term = other.Term
+	}
+	if other.Content != "" {
+		p.Content = other.Content
+	}
+
+	return p
+}
+
+// Apply adds the UTM parameters to the query string of rawURL, replacing
+// any utm_* values that are already present
+func (p UTMParams) Apply(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid destination url: %w", err)
+	}
+
+	query := u.Query()
+	for key, value := range map[string]string{
+		"utm_source":   p.Source,
+		"utm_medium":   p.Medium,
+		"utm_campaign": p.Campaign,
+		"utm_term":     p.Term,
+		"utm_content":  p.Content,
+	} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
@@ -0,0 +1,127 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WebhookEvent names an event delivered to webhook subscriptions
+type WebhookEvent string
+
+// Webhook events
+const (
+	WebhookLinkCreated WebhookEvent = "link.created"
+	WebhookLinkUpdated WebhookEvent = "link.updated"
+	WebhookLinkDeleted WebhookEvent = "link.deleted"
+	WebhookLinkExpired WebhookEvent = "link.expired"
+	WebhookLinkClicked WebhookEvent = "link.clicked"
+)
+
+// Webhook delivery statuses, dead deliveries ran out of attempts and form the dead-letter list
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusSucceeded = "succeeded"
+	DeliveryStatusDead      = "dead"
+)
+
+// WebhookSubscription sends the events of a workspace to an endpoint
+type WebhookSubscription struct {
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	WorkspaceID uint           `gorm:"not null;index" json:"workspace_id"`
+	URL         string         `gorm:"type:text;not null" json:"url"`
+	Secret      string         `gorm:"type:varchar(100);not null" json:"-"`
+	Events      string         `gorm:"type:varchar(255);not null" json:"events"`
+	Active      bool           `gorm:"not null;default:true" json:"active"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// WebhookDelivery is a queued or attempted delivery of an event to a subscription
+type WebhookDelivery struct {
+	ID             uint                `gorm:"primaryKey" json:"id"`
+	WorkspaceID    uint                `gorm:"not null;index" json:"workspace_id"`
+	SubscriptionID uint                `gorm:"not null;index;uniqueIndex:idx_webhook_deliveries_outbox_event,priority:1" json:"subscription_id"`
+	Subscription   WebhookSubscription `gorm:"foreignKey:SubscriptionID" json:"-"`
+	Event          WebhookEvent        `gorm:"type:varchar(50);not null" json:"event"`
+	Payload        JSON                `gorm:"type:jsonb;not null" json:"payload"`
+	Status         string              `gorm:"type:varchar(20);not null;default:pending;index:idx_webhook_deliveries_status_next,priority:1" json:"status"`
+	Attempts       int                 `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt  time.Time           `gorm:"index:idx_webhook_deliveries_status_next,priority:2" json:"next_attempt_at"`
+	LastStatusCode int                 `json:"last_status_code,omitempty"`
+	LastError      string              `gorm:"type:text" json:"last_error,omitempty"`
+	DeliveredAt    *time.Time          `json:"delivered_at,omitempty"`
+	ReplayOf       *uint               `json:"replay_of,omitempty"`
+	OutboxEventID  *uint               `gorm:"uniqueIndex:idx_webhook_deliveries_outbox_event,priority:2" json:"-"`
+	CreatedAt      time.Time           `json:"created_at"`
+	UpdatedAt      time.Time           `json:"updated_at"`
+}
+
+// WebhookPayload is the body posted to webhook endpoints
+type WebhookPayload struct {
+	Event       WebhookEvent `json:"event"`
+	WorkspaceID uint         `json:"workspace_id"`
+	OccurredAt  time.Time    `json:"occurred_at"`
+	Data        interface{}  `json:"data"`
+}
+
+// WebhookClickData is the data of link.clicked events
+type WebhookClickData struct {
+	LinkID    uint      `json:"link_id"`
+	ShortCode string    `json:"short_code"`
+	Source    string    `json:"source"`
+	Referer   string    `json:"referer,omitempty"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	ClickedAt time.Time `json:"clicked_at"`
+}
+
+// CreateWebhookRequest represents the request body for creating a webhook subscription
+type CreateWebhookRequest struct {
+	URL    string         `json:"url" binding:"required,url"`
+	Events []WebhookEvent `json:"events" binding:"required,min=1,dive,oneof=link.created link.updated link.deleted link.expired link.clicked"`
+}
+
+// CreateWebhookResponse represents the response body after creating a webhook
+// subscription, the only time its signing secret is shown
+type CreateWebhookResponse struct {
+	WebhookSubscription
+	Secret string `json:"secret"`
+}
+
+// ListWebhookDeliveriesRequest represents the query parameters for listing webhook deliveries
+type ListWebhookDeliveriesRequest struct {
+	Status string `form:"status" binding:"omitempty,oneof=pending succeeded dead"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int    `form:"offset" binding:"omitempty,min=0"`
+}
+
+// ListWebhookDeliveriesResponse represents a page of the delivery log of a subscription
+type ListWebhookDeliveriesResponse struct {
+	Deliveries []WebhookDelivery `json:"deliveries"`
+	Total      int64             `json:"total"`
+	Limit      int               `json:"limit"`
+	Offset     int               `json:"offset"`
+}
+
+// JoinWebhookEvents formats events for WebhookSubscription.Events
+func JoinWebhookEvents(events []WebhookEvent) string {
+	names := make([]string, len(events))
+	for i, event := range events {
+		names[i] = string(event)
+	}
+
+	return strings.Join(names, ",")
+}
+
+// Accepts reports whether the subscription wants an event
+func (s *WebhookSubscription) Accepts(event WebhookEvent) bool {
+	for _, name := range strings.Split(s.Events, ",") {
+		if WebhookEvent(name) == event {
+			return true
+		}
+	}
+
+	return false
+}
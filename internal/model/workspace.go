@@ -0,0 +1,69 @@
+package model
+
+import "time"
+
+// Role is the role of a user in a workspace
+type Role string
+
+// Workspace roles, from most to least privileged
+const (
+	RoleOwner  Role = "owner"
+	RoleAdmin  Role = "admin"
+	RoleEditor Role = "editor"
+	RoleViewer Role = "viewer"
+)
+
+var roleRanks = map[Role]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleAdmin:  3,
+	RoleOwner:  4,
+}
+
+// Workspace is a team owning links, domains and UTM templates
+type Workspace struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Name      string    `gorm:"type:varchar(100);not null" json:"name"`
+	Plan      string    `gorm:"type:varchar(20);not null;default:'free'" json:"plan"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Membership grants a user a role in a workspace
+type Membership struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	WorkspaceID uint      `gorm:"not null;uniqueIndex:idx_memberships_workspace_user" json:"workspace_id"`
+	Workspace   Workspace `gorm:"foreignKey:WorkspaceID" json:"-"`
+	UserID      string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_memberships_workspace_user;index" json:"user_id"`
+	Role        Role      `gorm:"type:varchar(20);not null" json:"role"`
+	InvitedBy   string    `gorm:"type:varchar(64)" json:"invited_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// CreateWorkspaceRequest represents the request body for creating a workspace
+type CreateWorkspaceRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// InviteMemberRequest represents the request body for inviting a workspace member
+type InviteMemberRequest struct {
+	UserID string `json:"user_id" binding:"required,max=64"`
+	Role   Role   `json:"role" binding:"required,oneof=owner admin editor viewer"`
+}
+
+// UpdateMemberRequest represents the request body for changing the role of a member
+type UpdateMemberRequest struct {
+	Role Role `json:"role" binding:"required,oneof=owner admin editor viewer"`
+}
+
+// IsValid reports whether r is a known role
+func (r Role) IsValid() bool {
+	_, ok := roleRanks[r]
+	return ok
+}
+
+// Allows reports whether r grants at least the permissions of required
+func (r Role) Allows(required Role) bool {
+	return r.IsValid() && roleRanks[r] >= roleRanks[required]
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"url_shortener/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// interface for abuse report repository operations
+type AbuseReportRepository interface {
+	Create(ctx context.Context, report *model.AbuseReport) error
+	FindByID(ctx context.Context, id uint) (*model.AbuseReport, error)
+	Find(ctx context.Context, status string, limit, offset int) ([]model.AbuseReport, int64, error)
+	ResolveOpen(ctx context.Context, urlID uint, resolution *model.AbuseReport) (int64, error)
+}
+
+// abuse report repository implements
+type AbuseReportRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// create a new abuse report repository
+func NewAbuseReportRepository(db *gorm.DB) AbuseReportRepository {
+	return &AbuseReportRepositoryImpl{
+		db: db,
+	}
+}
+
+// create a new abuse report
+func (r *AbuseReportRepositoryImpl) Create(ctx context.Context, report *model.AbuseReport) error {
+	return r.db.WithContext(ctx).Create(report).Error
+}
+
+// find an abuse report by id
+func (r *AbuseReportRepositoryImpl) FindByID(ctx context.Context, id uint) (*model.AbuseReport, error) {
+	var report model.AbuseReport
+	if err := r.db.WithContext(ctx).First(&report, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("abuse report %d not found", id)
+		}
+		return nil, fmt.Errorf("error finding abuse report: %w", err)
+	}
+
+	return &report, nil
+}
+
+// find a page of the abuse reports of every workspace, oldest first so the queue
+// is worked through in order, all statuses when status is empty
+func (r *AbuseReportRepositoryImpl) Find(ctx context.Context, status string, limit, offset int) ([]model.AbuseReport, int64, error) {
+	var reports []model.AbuseReport
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&model.AbuseReport{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("error counting abuse reports: %w", err)
+	}
+
+	if err := query.Order("created_at ASC, id ASC").Limit(limit).Offset(offset).Find(&reports).Error; err != nil {
+		return nil, 0, fmt.Errorf("error finding abuse reports: %w", err)
+	}
+
+	return reports, total, nil
+}
+
+// resolve every open report of a url with the decision of resolution,
+// returning the number of reports resolved
+func (r *AbuseReportRepositoryImpl) ResolveOpen(ctx context.Context, urlID uint, resolution *model.AbuseReport) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&model.AbuseReport{}).
+		Where("url_id = ? AND status = ?", urlID, model.AbuseReportOpen).
+		Updates(map[string]interface{}{
+			"status":          model.AbuseReportResolved,
+			"action":          resolution.Action,
+			"resolution_note": resolution.ResolutionNote,
+			"resolved_by":     resolution.ResolvedBy,
+			"resolved_at":     resolution.ResolvedAt,
+		})
+	if result.Error != nil {
+		return 0, fmt.Errorf("error resolving abuse reports: %w", result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"url_shortener/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// auditExportBatchSize is the number of audit events loaded at once while exporting
+const auditExportBatchSize = 500
+
+// interface for audit log repository operations, the log being append-only
+type AuditRepository interface {
+	Create(ctx context.Context, event *model.AuditEvent) error
+	Find(ctx context.Context, workspaceID uint, query model.AuditQuery) ([]model.AuditEvent, int64, error)
+	Each(ctx context.Context, workspaceID uint, query model.AuditQuery, fn func(event *model.AuditEvent) error) error
+}
+
+// audit log repository implements
+type AuditRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// create a new audit log repository
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &AuditRepositoryImpl{
+		db: db,
+	}
+}
+
+// append an event to the audit log
+func (r *AuditRepositoryImpl) Create(ctx context.Context, event *model.AuditEvent) error {
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+// find a page of the audit events of a workspace matching a query, newest first
+func (r *AuditRepositoryImpl) Find(ctx context.Context, workspaceID uint, query model.AuditQuery) ([]model.AuditEvent, int64, error) {
+	var events []model.AuditEvent
+	var total int64
+
+	db := r.filter(ctx, workspaceID, query)
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("error counting audit events: %w", err)
+	}
+
+	if err := db.Order("created_at DESC, id DESC").Limit(query.Limit).Offset(query.Offset).Find(&events).Error; err != nil {
+		return nil, 0, fmt.Errorf("error finding audit events: %w", err)
+	}
+
+	return events, total, nil
+}
+
+// call fn on every audit event of a workspace matching a query, oldest first,
+// loading them in batches
+func (r *AuditRepositoryImpl) Each(ctx context.Context, workspaceID uint, query model.AuditQuery, fn func(event *model.AuditEvent) error) error {
+	var batch []model.AuditEvent
+	result := r.filter(ctx, workspaceID, query).FindInBatches(&batch, auditExportBatchSize, func(tx *gorm.DB, _ int) error {
+		for i := range batch {
+			if err := fn(&batch[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if result.Error != nil {
+		return fmt.Errorf("error exporting audit events: %w", result.Error)
+	}
+
+	return nil
+}
+
+// filter scopes the audit events to a workspace and a query
+func (r *AuditRepositoryImpl) filter(ctx context.Context, workspaceID uint, query model.AuditQuery) *gorm.DB {
+	db := r.db.WithContext(ctx).Model(&model.AuditEvent{}).Where("workspace_id = ?", workspaceID)
+
+	if query.ActorID != "" {
+		db = db.Where("actor_id = ?", query.ActorID)
+	}
+	if query.Action != "" {
+		db = db.Where("action = ?", query.Action)
+	}
+	if query.TargetType != "" {
+		db = db.Where("target_type = ?", query.TargetType)
+	}
+	if query.TargetID != "" {
+		db = db.Where("target_id = ?", query.TargetID)
+	}
+	if query.RequestID != "" {
+		db = db.Where("request_id = ?", query.RequestID)
+	}
+	if query.From != nil {
+		db = db.Where("created_at >= ?", *query.From)
+	}
+	if query.To != nil {
+		db = db.Where("created_at < ?", *query.To)
+	}
+
+	return db
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"url_shortener/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// interface for domain repository operations
+type DomainRepository interface {
+	Create(ctx context.Context, domain *model.Domain) error
+	FindByID(ctx context.Context, id uint) (*model.Domain, error)
+	FindByHost(ctx context.Context, host string) (*model.Domain, error)
+	FindDefault(ctx context.Context) (*model.Domain, error)
+	FindAll(ctx context.Context) ([]model.Domain, error)
+	FindAllForWorkspace(ctx context.Context, workspaceID uint) ([]model.Domain, error)
+	Update(ctx context.Context, domain *model.Domain) error
+	AssignURLsWithoutDomain(ctx context.Context, domainID uint) (int64, error)
+}
+
+// domain repository implements
+type DomainRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// create a new domain repository
+func NewDomainRepository(db *gorm.DB) DomainRepository {
+	return &DomainRepositoryImpl{
+		db: db,
+	}
+}
+
+// create a new domain in database
+func (r *DomainRepositoryImpl) Create(ctx context.Context, domain *model.Domain) error {
+	return r.db.WithContext(ctx).Create(domain).Error
+}
+
+// find domain by id
+func (r *DomainRepositoryImpl) FindByID(ctx context.Context, id uint) (*model.Domain, error) {
+	var domain model.Domain
+	err := r.db.WithContext(ctx).First(&domain, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("domain %d not found", id)
+		}
+		return nil, fmt.Errorf("error finding domain: %w", err)
+	}
+
+	return &domain, nil
+}
+
+// find domain by host
+func (r *DomainRepositoryImpl) FindByHost(ctx context.Context, host string) (*model.Domain, error) {
+	var domain model.Domain
+	err := r.db.WithContext(ctx).Where("host = ?", host).First(&domain).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("domain %s not found", host)
+		}
+		return nil, fmt.Errorf("error finding domain: %w", err)
+	}
+
+	return &domain, nil
+}
+
+// find the default domain
+func (r *DomainRepositoryImpl) FindDefault(ctx context.Context) (*model.Domain, error) {
+	var domain model.Domain
+	err := r.db.WithContext(ctx).Where("is_default = ?", true).First(&domain).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("default domain not found")
+		}
+		return nil, fmt.Errorf("error finding default domain: %w", err)
+	}
+
+	return &domain, nil
+}
+
+// find all domains
+func (r *DomainRepositoryImpl) FindAll(ctx context.Context) ([]model.Domain, error) {
+	var domains []model.Domain
+	if err := r.db.WithContext(ctx).Order("host").Find(&domains).Error; err != nil {
+		return nil, fmt.Errorf("error finding domains: %w", err)
+	}
+
+	return domains, nil
+}
+
+// find the shared domains and the domains of a workspace
+func (r *DomainRepositoryImpl) FindAllForWorkspace(ctx context.Context, workspaceID uint) ([]model.Domain, error) {
+	var domains []model.Domain
+	if err := r.db.WithContext(ctx).Where("workspace_id IS NULL OR workspace_id = ?", workspaceID).Order("host").Find(&domains).Error; err != nil {
+		return nil, fmt.Errorf("error finding domains: %w", err)
+	}
+
+	return domains, nil
+}
+
+// update a domain
+func (r *DomainRepositoryImpl) Update(ctx context.Context, domain *model.Domain) error {
+	return r.db.WithContext(ctx).Save(domain).Error
+}
+
+// assign urls created before domains existed to the given domain
+func (r *DomainRepositoryImpl) AssignURLsWithoutDomain(ctx context.Context, domainID uint) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&model.URL{}).Where("domain_id = ?", 0).UpdateColumn("domain_id", domainID)
+
+	return result.RowsAffected, result.Error
+}
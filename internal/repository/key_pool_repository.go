@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"url_shortener/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// interface for key pool repository operations
+type KeyPoolRepository interface {
+	Insert(ctx context.Context, codes []string) (int64, error)
+	Claim(ctx context.Context, limit int) ([]string, error)
+	Reserve(ctx context.Context, code string) error
+	CountAvailable(ctx context.Context) (int64, error)
+	TryLock(ctx context.Context) (func(), bool, error)
+}
+
+// keyPoolLockID is the postgres advisory lock taken while filling the pool
+const keyPoolLockID = 0x6b6579706f6f6c
+
+// key pool repository implements
+type KeyPoolRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// create a new key pool repository
+func NewKeyPoolRepository(db *gorm.DB) KeyPoolRepository {
+	return &KeyPoolRepositoryImpl{
+		db: db,
+	}
+}
+
+// add codes to the pool, skipping the ones already pooled or used by a url,
+// and return the number of codes added
+func (r *KeyPoolRepositoryImpl) Insert(ctx context.Context, codes []string) (int64, error) {
+	var used []string
+	if err := r.db.WithContext(ctx).Model(&model.URL{}).Unscoped().Where("short_code IN ?", codes).Pluck("short_code", &used).Error; err != nil {
+		return 0, fmt.Errorf("error finding used codes: %w", err)
+	}
+
+	skip := make(map[string]bool, len(used))
+	for _, code := range used {
+		skip[code] = true
+	}
+
+	now := time.Now()
+	keys := make([]model.PoolKey, 0, len(codes))
+	for _, code := range codes {
+		if !skip[code] {
+			skip[code] = true
+			keys = append(keys, model.PoolKey{Code: code, CreatedAt: now})
+		}
+	}
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&keys)
+	if result.Error != nil {
+		return 0, fmt.Errorf("error filling key pool: %w", result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
+
+// atomically claim up to limit unclaimed codes, skipping the ones other
+// replicas are claiming at the same time
+func (r *KeyPoolRepositoryImpl) Claim(ctx context.Context, limit int) ([]string, error) {
+	var codes []string
+	err := r.db.WithContext(ctx).Raw(`
+		UPDATE key_pool SET claimed_at = ?
+		WHERE code IN (
+			SELECT code FROM key_pool WHERE claimed_at IS NULL
+			LIMIT ? FOR UPDATE SKIP LOCKED
+		)
+		RETURNING code`, time.Now(), limit).Scan(&codes).Error
+	if err != nil {
+		return nil, fmt.Errorf("error claiming keys: %w", err)
+	}
+
+	return codes, nil
+}
+
+// mark a code taken outside the pool as claimed, so the pool never hands it out
+func (r *KeyPoolRepositoryImpl) Reserve(ctx context.Context, code string) error {
+	now := time.Now()
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "code"}},
+		DoUpdates: clause.AssignmentColumns([]string{"claimed_at"}),
+	}).Create(&model.PoolKey{Code: code, ClaimedAt: &now, CreatedAt: now}).Error
+}
+
+// count the unclaimed codes of the pool
+func (r *KeyPoolRepositoryImpl) CountAvailable(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&model.PoolKey{}).Where("claimed_at IS NULL").Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("error counting pooled keys: %w", err)
+	}
+
+	return count, nil
+}
+
+// take the advisory lock of the pool filling unless another replica holds it,
+// returning the function releasing it
+func (r *KeyPoolRepositoryImpl) TryLock(ctx context.Context) (func(), bool, error) {
+	sqlDB, err := r.db.DB()
+	if err != nil {
+		return nil, false, err
+	}
+
+	// advisory locks belong to a session, so the lock is held on one connection
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("error locking key pool: %w", err)
+	}
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", keyPoolLockID).Scan(&locked); err != nil {
+		conn.Close()
+		return nil, false, fmt.Errorf("error locking key pool: %w", err)
+	}
+	if !locked {
+		conn.Close()
+		return nil, false, nil
+	}
+
+	unlock := func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", keyPoolLockID); err != nil {
+			log.Printf("error unlocking key pool: %v", err)
+		}
+		conn.Close()
+	}
+
+	return unlock, true, nil
+}
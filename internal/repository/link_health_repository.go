@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"url_shortener/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// interface for link health repository operations
+type LinkHealthRepository interface {
+	FindByURLID(ctx context.Context, urlID uint) (*model.LinkHealth, error)
+	FindByURLIDs(ctx context.Context, urlIDs []uint) (map[uint]model.LinkHealth, error)
+	Upsert(ctx context.Context, health *model.LinkHealth) error
+	FindBroken(ctx context.Context, workspaceID uint, limit, offset int) ([]model.LinkHealth, int64, error)
+}
+
+// link health repository implements
+type LinkHealthRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// create a new link health repository
+func NewLinkHealthRepository(db *gorm.DB) LinkHealthRepository {
+	return &LinkHealthRepositoryImpl{
+		db: db,
+	}
+}
+
+// find the health of a url
+func (r *LinkHealthRepositoryImpl) FindByURLID(ctx context.Context, urlID uint) (*model.LinkHealth, error) {
+	var health model.LinkHealth
+	if err := r.db.WithContext(ctx).Where("url_id = ?", urlID).First(&health).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("url %d was never checked", urlID)
+		}
+		return nil, fmt.Errorf("error finding link health: %w", err)
+	}
+
+	return &health, nil
+}
+
+// find the health of urls, keyed by url id
+func (r *LinkHealthRepositoryImpl) FindByURLIDs(ctx context.Context, urlIDs []uint) (map[uint]model.LinkHealth, error) {
+	var healths []model.LinkHealth
+	if err := r.db.WithContext(ctx).Where("url_id IN ?", urlIDs).Find(&healths).Error; err != nil {
+		return nil, fmt.Errorf("error finding link health: %w", err)
+	}
+
+	byURL := make(map[uint]model.LinkHealth, len(healths))
+	for _, health := range healths {
+		byURL[health.URLID] = health
+	}
+
+	return byURL, nil
+}
+
+// create or replace the health of a url
+func (r *LinkHealthRepositoryImpl) Upsert(ctx context.Context, health *model.LinkHealth) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "url_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"workspace_id", "status_code", "latency_ms", "final_url", "last_error", "consecutive_failures", "broken", "checked_at", "broken_since"}),
+	}).Create(health).Error
+}
+
+// find a page of the broken links of a workspace with their url, longest broken first
+func (r *LinkHealthRepositoryImpl) FindBroken(ctx context.Context, workspaceID uint, limit, offset int) ([]model.LinkHealth, int64, error) {
+	var healths []model.LinkHealth
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&model.LinkHealth{}).
+		Joins("JOIN urls ON urls.id = link_health.url_id AND urls.deleted_at IS NULL").
+		Where("link_health.workspace_id = ? AND link_health.broken = ?", workspaceID, true)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("error counting broken links: %w", err)
+	}
+
+	if err := query.Preload("URL").Order("link_health.broken_since ASC, link_health.url_id ASC").Limit(limit).Offset(offset).Find(&healths).Error; err != nil {
+		return nil, 0, fmt.Errorf("error finding broken links: %w", err)
+	}
+
+	return healths, total, nil
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"url_shortener/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// interface for outbox repository operations
+type OutboxRepository interface {
+	ProcessPending(ctx context.Context, limit int, fn func(events []model.OutboxEvent) error) (int, error)
+	DeletePublishedBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// outbox repository implements
+type OutboxRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// create a new outbox repository
+func NewOutboxRepository(db *gorm.DB) OutboxRepository {
+	return &OutboxRepositoryImpl{
+		db: db,
+	}
+}
+
+// lock up to limit unpublished events, oldest first, and hand them to fn. The
+// events are marked as published when fn succeeds, and their attempt is
+// recorded otherwise. Locked rows are skipped by other relays
+func (r *OutboxRepositoryImpl) ProcessPending(ctx context.Context, limit int, fn func(events []model.OutboxEvent) error) (int, error) {
+	var events []model.OutboxEvent
+	var publishErr error
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("published_at IS NULL").
+			Order("id").
+			Limit(limit).
+			Find(&events).Error
+		if err != nil || len(events) == 0 {
+			return err
+		}
+
+		ids := make([]uint, len(events))
+		for i, event := range events {
+			ids[i] = event.ID
+		}
+
+		if publishErr = fn(events); publishErr != nil {
+			return tx.Model(&model.OutboxEvent{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+				"attempts":   gorm.Expr("attempts + 1"),
+				"last_error": publishErr.Error(),
+			}).Error
+		}
+
+		return tx.Model(&model.OutboxEvent{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"published_at": time.Now(),
+			"last_error":   "",
+		}).Error
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error processing outbox events: %w", err)
+	}
+	if publishErr != nil {
+		return 0, fmt.Errorf("error publishing outbox events: %w", publishErr)
+	}
+
+	return len(events), nil
+}
+
+// delete the events published before a time
+func (r *OutboxRepositoryImpl) DeletePublishedBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("published_at < ?", before).Delete(&model.OutboxEvent{})
+
+	return result.RowsAffected, result.Error
+}
@@ -14,11 +14,20 @@ import (
 // interface for URL repository operations
 type URLRepository interface {
 	Create(ctx context.Context, url *model.URL) error
-	FindByShortCode(ctx context.Context, shortCode string) (*model.URL, error)
+	Update(ctx context.Context, url *model.URL) error
+	Delete(ctx context.Context, url *model.URL) error
+	FindByShortCode(ctx context.Context, domainID uint, shortCode string) (*model.URL, error)
+	FindActiveByShortCodes(ctx context.Context, domainID uint, codes []string) ([]model.URL, error)
+	FindTakenCodes(ctx context.Context, domainID uint, codes []string) ([]string, error)
+	FindActiveByDestination(ctx context.Context, workspaceID, domainID uint, destinationHash string) ([]model.URL, error)
 	IncrementVisitCount(ctx context.Context, id uint) error
 	CreateVisit(ctx context.Context, visit *model.URLVisit) error
-	FindAllByUser(ctx context.Context, userID uint, limit, offset int) ([]model.URL, int64, error)
-	DeleteExpired(ctx context.Context) (int64, error)
+	FindAllByWorkspace(ctx context.Context, workspaceID uint, limit, offset int) ([]model.URL, int64, error)
+	DeleteExpired(ctx context.Context) ([]model.URL, error)
+	EachActive(ctx context.Context, batchSize int, fn func(urls []model.URL) error) error
+	UpdateMetadata(ctx context.Context, id uint, metadata model.LinkMetadata) error
+	CountVisitsByCampaign(ctx context.Context, workspaceID uint) ([]model.CampaignStats, error)
+	CountByCodeLength(ctx context.Context) (map[int]int64, error)
 }
 
 // url repository implements
@@ -33,15 +42,43 @@ func NewURLRepository(db *gorm.DB) URLRepository {
 	}
 }
 
-// create a new url in database
+// create a new url in database, along with its outbox event
 func (r *URLRepositoryImpl) Create(ctx context.Context, url *model.URL) error {
-	return r.db.WithContext(ctx).Create(url).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(url).Error; err != nil {
+			return err
+		}
+
+		return writeLinkEvent(tx, model.EventLinkCreated, url)
+	})
+}
+
+// update a url, along with its outbox event
+func (r *URLRepositoryImpl) Update(ctx context.Context, url *model.URL) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(url).Error; err != nil {
+			return err
+		}
+
+		return writeLinkEvent(tx, model.EventLinkUpdated, url)
+	})
 }
 
-// find url by short code
-func (r *URLRepositoryImpl) FindByShortCode(ctx context.Context, shortCode string) (*model.URL, error) {
+// delete a url, along with its outbox event
+func (r *URLRepositoryImpl) Delete(ctx context.Context, url *model.URL) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(url).Error; err != nil {
+			return err
+		}
+
+		return writeLinkEvent(tx, model.EventLinkDeleted, url)
+	})
+}
+
+// find url by short code within a domain
+func (r *URLRepositoryImpl) FindByShortCode(ctx context.Context, domainID uint, shortCode string) (*model.URL, error) {
 	var url model.URL
-	err := r.db.WithContext(ctx).Where("short_code = ?", shortCode).First(&url).Error
+	err := r.db.WithContext(ctx).Where("domain_id = ? AND short_code = ?", domainID, shortCode).First(&url).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, fmt.Errorf("URL with short code %s not found", shortCode)
@@ -57,7 +94,7 @@ func (r *URLRepositoryImpl) FindByShortCode(ctx context.Context, shortCode strin
 }
 
 // increment visit count for the url
-func (r *URLRepositoryImpl) IncrementVisitCount(ctx context.Context, id int) error {
+func (r *URLRepositoryImpl) IncrementVisitCount(ctx context.Context, id uint) error {
 	return r.db.WithContext(ctx).Model(&model.URL{}).Where("id = ?", id).UpdateColumn("visit_count", gorm.Expr("visit_count + ?", 1)).Error
 }
 
@@ -66,25 +103,168 @@ func (r *URLRepositoryImpl) CreateVisit(ctx context.Context, visit *model.URLVis
 	return r.db.WithContext(ctx).Create(visit).Error
 }
 
-// find all url created by a specific user
-func (r *URLRepositoryImpl) FindAllByUser(ctx context.Context, userID uint, limit, offset int) ([]model.URL, int64, error) {
+// find all url of a workspace
+func (r *URLRepositoryImpl) FindAllByWorkspace(ctx context.Context, workspaceID uint, limit, offset int) ([]model.URL, int64, error) {
 	var urls []model.URL
 	var total int64
 
-	if err := r.db.WithContext(ctx).Model(&model.URL{}).Count(&total).Error; err != nil {
+	query := r.db.WithContext(ctx).Model(&model.URL{}).Where("workspace_id = ?", workspaceID)
+	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, fmt.Errorf("error counting urls: %w", err)
 	}
 
-	if err := r.db.WithContext(ctx).Order("created_at DESC").Limit(limit).Offset(offset).Find(&urls).Error; err != nil {
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&urls).Error; err != nil {
 		return nil, 0, fmt.Errorf("error finding urls: %w", err)
 	}
 
 	return urls, total, nil
 }
 
-// delete all expired urls
-func (r *URLRepositoryImpl) DeleteExpired(ctx context.Context) (int64, error) {
-	result := r.db.WithContext(ctx).Where("expires_at < ? AND expires_at IS NOT NULL", time.Now()).Delete(&model.URL{})
+// find the enabled, unexpired urls of a domain with one of the codes
+func (r *URLRepositoryImpl) FindActiveByShortCodes(ctx context.Context, domainID uint, codes []string) ([]model.URL, error) {
+	var urls []model.URL
+	err := r.db.WithContext(ctx).
+		Where("domain_id = ? AND short_code IN ?", domainID, codes).
+		Where("disabled = ? AND (expires_at IS NULL OR expires_at > ?)", false, time.Now()).
+		Find(&urls).Error
+	if err != nil {
+		return nil, fmt.Errorf("error finding urls by short codes: %w", err)
+	}
+
+	return urls, nil
+}
+
+// find which of the codes are taken on a domain, deleted urls included since
+// their codes stay taken
+func (r *URLRepositoryImpl) FindTakenCodes(ctx context.Context, domainID uint, codes []string) ([]string, error) {
+	var taken []string
+	err := r.db.WithContext(ctx).Unscoped().Model(&model.URL{}).
+		Where("domain_id = ? AND short_code IN ?", domainID, codes).
+		Pluck("short_code", &taken).Error
+	if err != nil {
+		return nil, fmt.Errorf("error finding taken codes: %w", err)
+	}
+
+	return taken, nil
+}
+
+// find the enabled, unexpired urls of a workspace on a domain whose
+// normalized destination has the given hash
+func (r *URLRepositoryImpl) FindActiveByDestination(ctx context.Context, workspaceID, domainID uint, destinationHash string) ([]model.URL, error) {
+	var urls []model.URL
+	err := r.db.WithContext(ctx).
+		Where("workspace_id = ? AND domain_id = ? AND destination_hash = ?", workspaceID, domainID, destinationHash).
+		Where("disabled = ? AND (expires_at IS NULL OR expires_at > ?)", false, time.Now()).
+		Order("id").
+		Find(&urls).Error
+	if err != nil {
+		return nil, fmt.Errorf("error finding urls by destination: %w", err)
+	}
+
+	return urls, nil
+}
+
+// call fn on every enabled, unexpired url, loading them in batches
+func (r *URLRepositoryImpl) EachActive(ctx context.Context, batchSize int, fn func(urls []model.URL) error) error {
+	var batch []model.URL
+	result := r.db.WithContext(ctx).
+		Where("disabled = ? AND (expires_at IS NULL OR expires_at > ?)", false, time.Now()).
+		FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+			return fn(batch)
+		})
+	if result.Error != nil {
+		return fmt.Errorf("error finding active urls: %w", result.Error)
+	}
+
+	return nil
+}
+
+// update the destination metadata of a url, leaving its other columns and
+// updated_at untouched
+func (r *URLRepositoryImpl) UpdateMetadata(ctx context.Context, id uint, metadata model.LinkMetadata) error {
+	return r.db.WithContext(ctx).Model(&model.URL{}).Where("id = ?", id).UpdateColumns(map[string]interface{}{
+		"meta_title":       metadata.Title,
+		"meta_description": metadata.Description,
+		"meta_favicon_url": metadata.FaviconURL,
+		"meta_image_url":   metadata.ImageURL,
+		"meta_fetch_error": metadata.FetchError,
+		"meta_fetched_at":  metadata.FetchedAt,
+	}).Error
+}
+
+// delete all expired urls and return them
+func (r *URLRepositoryImpl) DeleteExpired(ctx context.Context) ([]model.URL, error) {
+	var urls []model.URL
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("expires_at < ? AND expires_at IS NOT NULL", time.Now()).Find(&urls).Error; err != nil {
+			return err
+		}
+		if len(urls) == 0 {
+			return nil
+		}
+
+		if err := tx.Delete(&urls).Error; err != nil {
+			return err
+		}
+
+		for i := range urls {
+			if err := writeLinkEvent(tx, model.EventLinkExpired, &urls[i]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return urls, err
+}
+
+// writeLinkEvent writes the outbox event of a link change within the transaction of the change
+func writeLinkEvent(tx *gorm.DB, eventType string, url *model.URL) error {
+	event, err := model.NewLinkEvent(eventType, url)
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(event).Error
+}
+
+// count visits to the urls of a workspace grouped by UTM campaign
+func (r *URLRepositoryImpl) CountVisitsByCampaign(ctx context.Context, workspaceID uint) ([]model.CampaignStats, error) {
+	var stats []model.CampaignStats
+	err := r.db.WithContext(ctx).Model(&model.URLVisit{}).
+		Select("url_visits.utm_campaign AS campaign, COUNT(*) AS clicks, COUNT(DISTINCT url_visits.url_id) AS links").
+		Joins("JOIN urls ON urls.id = url_visits.url_id").
+		Where("urls.workspace_id = ? AND url_visits.utm_campaign <> ''", workspaceID).
+		Group("url_visits.utm_campaign").
+		Order("clicks DESC").
+		Scan(&stats).Error
+	if err != nil {
+		return nil, fmt.Errorf("error counting visits by campaign: %w", err)
+	}
+
+	return stats, nil
+}
+
+// count the short codes of every length, deleted urls included since their
+// codes stay taken
+func (r *URLRepositoryImpl) CountByCodeLength(ctx context.Context) (map[int]int64, error) {
+	var rows []struct {
+		Length int
+		Count  int64
+	}
+	err := r.db.WithContext(ctx).Unscoped().Model(&model.URL{}).
+		Select("LENGTH(short_code) AS length, COUNT(*) AS count").
+		Group("LENGTH(short_code)").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("error counting codes by length: %w", err)
+	}
+
+	counts := make(map[int]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Length] = row.Count
+	}
 
-	return result.RowsAffected, result.Error
+	return counts, nil
 }
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"url_shortener/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// interface for usage counter repository operations
+type UsageRepository interface {
+	Find(ctx context.Context, workspaceID uint, period string) (*model.UsageCounter, error)
+	Upsert(ctx context.Context, counter *model.UsageCounter) error
+}
+
+// usage counter repository implements
+type UsageRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// create a new usage counter repository
+func NewUsageRepository(db *gorm.DB) UsageRepository {
+	return &UsageRepositoryImpl{
+		db: db,
+	}
+}
+
+// find the usage counter of a workspace for a period, empty when nothing was recorded yet
+func (r *UsageRepositoryImpl) Find(ctx context.Context, workspaceID uint, period string) (*model.UsageCounter, error) {
+	counter := model.UsageCounter{WorkspaceID: workspaceID, Period: period}
+	err := r.db.WithContext(ctx).Where("workspace_id = ? AND period = ?", workspaceID, period).First(&counter).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("error finding usage counter: %w", err)
+	}
+
+	return &counter, nil
+}
+
+// insert or raise the usage counter of a workspace for a period, counters never decrease
+func (r *UsageRepositoryImpl) Upsert(ctx context.Context, counter *model.UsageCounter) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "workspace_id"}, {Name: "period"}},
+		DoUpdates: clause.Set{
+			{Column: clause.Column{Name: "links"}, Value: gorm.Expr("GREATEST(usage_counters.links, excluded.links)")},
+			{Column: clause.Column{Name: "redirects"}, Value: gorm.Expr("GREATEST(usage_counters.redirects, excluded.redirects)")},
+			{Column: clause.Column{Name: "api_calls"}, Value: gorm.Expr("GREATEST(usage_counters.api_calls, excluded.api_calls)")},
+			{Column: clause.Column{Name: "updated_at"}, Value: gorm.Expr("excluded.updated_at")},
+		},
+	}).Create(counter).Error
+}
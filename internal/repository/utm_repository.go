@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"url_shortener/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// interface for UTM template repository operations
+type UTMTemplateRepository interface {
+	Create(ctx context.Context, template *model.UTMTemplate) error
+	FindByID(ctx context.Context, id uint) (*model.UTMTemplate, error)
+	FindAllForUser(ctx context.Context, workspaceID uint, userID string) ([]model.UTMTemplate, error)
+	Delete(ctx context.Context, id uint) error
+}
+
+// UTM template repository implements
+type UTMTemplateRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// create a new UTM template repository
+func NewUTMTemplateRepository(db *gorm.DB) UTMTemplateRepository {
+	return &UTMTemplateRepositoryImpl{
+		db: db,
+	}
+}
+
+// create a new UTM template in database
+func (r *UTMTemplateRepositoryImpl) Create(ctx context.Context, template *model.UTMTemplate) error {
+	return r.db.WithContext(ctx).Create(template).Error
+}
+
+// find UTM template by id
+func (r *UTMTemplateRepositoryImpl) FindByID(ctx context.Context, id uint) (*model.UTMTemplate, error) {
+	var template model.UTMTemplate
+	err := r.db.WithContext(ctx).First(&template, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("UTM template %d not found", id)
+		}
+		return nil, fmt.Errorf("error finding UTM template: %w", err)
+	}
+
+	return &template, nil
+}
+
+// find the shared UTM templates of a workspace and the personal ones of a user
+func (r *UTMTemplateRepositoryImpl) FindAllForUser(ctx context.Context, workspaceID uint, userID string) ([]model.UTMTemplate, error) {
+	var templates []model.UTMTemplate
+	err := r.db.WithContext(ctx).
+		Where("workspace_id = ? AND (owner_id = '' OR owner_id = ?)", workspaceID, userID).
+		Order("name").
+		Find(&templates).Error
+	if err != nil {
+		return nil, fmt.Errorf("error finding UTM templates: %w", err)
+	}
+
+	return templates, nil
+}
+
+// delete a UTM template
+func (r *UTMTemplateRepositoryImpl) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&model.UTMTemplate{}, id).Error
+}
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"url_shortener/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// interface for webhook repository operations
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, subscription *model.WebhookSubscription) error
+	FindSubscription(ctx context.Context, id uint) (*model.WebhookSubscription, error)
+	FindSubscriptions(ctx context.Context, workspaceID uint) ([]model.WebhookSubscription, error)
+	FindActiveSubscriptions(ctx context.Context, workspaceID uint) ([]model.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, subscription *model.WebhookSubscription) error
+	CreateDeliveries(ctx context.Context, deliveries []model.WebhookDelivery) error
+	FindDelivery(ctx context.Context, id uint) (*model.WebhookDelivery, error)
+	FindDeliveries(ctx context.Context, subscriptionID uint, status string, limit, offset int) ([]model.WebhookDelivery, int64, error)
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]model.WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery *model.WebhookDelivery) error
+}
+
+// webhook repository implements
+type WebhookRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// create a new webhook repository
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &WebhookRepositoryImpl{
+		db: db,
+	}
+}
+
+// create a new webhook subscription in database
+func (r *WebhookRepositoryImpl) CreateSubscription(ctx context.Context, subscription *model.WebhookSubscription) error {
+	return r.db.WithContext(ctx).Create(subscription).Error
+}
+
+// find webhook subscription by id
+func (r *WebhookRepositoryImpl) FindSubscription(ctx context.Context, id uint) (*model.WebhookSubscription, error) {
+	var subscription model.WebhookSubscription
+	err := r.db.WithContext(ctx).First(&subscription, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("webhook %d not found", id)
+		}
+		return nil, fmt.Errorf("error finding webhook: %w", err)
+	}
+
+	return &subscription, nil
+}
+
+// find all webhook subscriptions of a workspace
+func (r *WebhookRepositoryImpl) FindSubscriptions(ctx context.Context, workspaceID uint) ([]model.WebhookSubscription, error) {
+	var subscriptions []model.WebhookSubscription
+	if err := r.db.WithContext(ctx).Where("workspace_id = ?", workspaceID).Order("created_at").Find(&subscriptions).Error; err != nil {
+		return nil, fmt.Errorf("error finding webhooks: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+// find the active webhook subscriptions of a workspace
+func (r *WebhookRepositoryImpl) FindActiveSubscriptions(ctx context.Context, workspaceID uint) ([]model.WebhookSubscription, error) {
+	var subscriptions []model.WebhookSubscription
+	if err := r.db.WithContext(ctx).Where("workspace_id = ? AND active = ?", workspaceID, true).Find(&subscriptions).Error; err != nil {
+		return nil, fmt.Errorf("error finding webhooks: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+// delete a webhook subscription
+func (r *WebhookRepositoryImpl) DeleteSubscription(ctx context.Context, subscription *model.WebhookSubscription) error {
+	return r.db.WithContext(ctx).Delete(subscription).Error
+}
+
+// enqueue webhook deliveries
+func (r *WebhookRepositoryImpl) CreateDeliveries(ctx context.Context, deliveries []model.WebhookDelivery) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Clauses(clause.OnConflict{DoNothing: true}).Create(&deliveries).Error
+}
+
+// find webhook delivery by id
+func (r *WebhookRepositoryImpl) FindDelivery(ctx context.Context, id uint) (*model.WebhookDelivery, error) {
+	var delivery model.WebhookDelivery
+	err := r.db.WithContext(ctx).First(&delivery, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("webhook delivery %d not found", id)
+		}
+		return nil, fmt.Errorf("error finding webhook delivery: %w", err)
+	}
+
+	return &delivery, nil
+}
+
+// find the deliveries of a subscription, newest first, optionally filtered by status
+func (r *WebhookRepositoryImpl) FindDeliveries(ctx context.Context, subscriptionID uint, status string, limit, offset int) ([]model.WebhookDelivery, int64, error) {
+	var deliveries []model.WebhookDelivery
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&model.WebhookDelivery{}).Where("subscription_id = ?", subscriptionID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("error counting webhook deliveries: %w", err)
+	}
+
+	if err := query.Order("created_at DESC, id DESC").Limit(limit).Offset(offset).Find(&deliveries).Error; err != nil {
+		return nil, 0, fmt.Errorf("error finding webhook deliveries: %w", err)
+	}
+
+	return deliveries, total, nil
+}
+
+// claim up to limit pending deliveries that are due, along with their subscription.
+// Claimed deliveries are pushed back by lease so that other replicas skip them
+// while they are being delivered
+func (r *WebhookRepositoryImpl) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]model.WebhookDelivery, error) {
+	var ids []uint
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.WebhookDelivery{}).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", model.DeliveryStatusPending, time.Now()).
+			Order("next_attempt_at").
+			Limit(limit).
+			Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+
+		return tx.Model(&model.WebhookDelivery{}).Where("id IN ?", ids).UpdateColumn("next_attempt_at", time.Now().Add(lease)).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error claiming webhook deliveries: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var deliveries []model.WebhookDelivery
+	if err := r.db.WithContext(ctx).Preload("Subscription").Where("id IN ?", ids).Find(&deliveries).Error; err != nil {
+		return nil, fmt.Errorf("error finding webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// update a webhook delivery
+func (r *WebhookRepositoryImpl) UpdateDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(delivery).Error
+}
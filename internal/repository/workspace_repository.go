@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"url_shortener/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// interface for workspace repository operations
+type WorkspaceRepository interface {
+	CreateWithOwner(ctx context.Context, workspace *model.Workspace, ownerID string) error
+	FindByID(ctx context.Context, id uint) (*model.Workspace, error)
+	UpdatePlan(ctx context.Context, id uint, plan string) error
+	FindAllByUser(ctx context.Context, userID string) ([]model.Workspace, error)
+	FindMembership(ctx context.Context, workspaceID uint, userID string) (*model.Membership, error)
+	FindMembers(ctx context.Context, workspaceID uint) ([]model.Membership, error)
+	CountMembersWithRole(ctx context.Context, workspaceID uint, role model.Role) (int64, error)
+	CreateMembership(ctx context.Context, membership *model.Membership) error
+	UpdateMembership(ctx context.Context, membership *model.Membership) error
+	DeleteMembership(ctx context.Context, membership *model.Membership) error
+}
+
+// workspace repository implements
+type WorkspaceRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// create a new workspace repository
+func NewWorkspaceRepository(db *gorm.DB) WorkspaceRepository {
+	return &WorkspaceRepositoryImpl{
+		db: db,
+	}
+}
+
+// create a workspace along with the membership of its owner
+func (r *WorkspaceRepositoryImpl) CreateWithOwner(ctx context.Context, workspace *model.Workspace, ownerID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(workspace).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&model.Membership{
+			WorkspaceID: workspace.ID,
+			UserID:      ownerID,
+			Role:        model.RoleOwner,
+		}).Error
+	})
+}
+
+// find workspace by id
+func (r *WorkspaceRepositoryImpl) FindByID(ctx context.Context, id uint) (*model.Workspace, error) {
+	var workspace model.Workspace
+	err := r.db.WithContext(ctx).First(&workspace, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("workspace %d not found", id)
+		}
+		return nil, fmt.Errorf("error finding workspace: %w", err)
+	}
+
+	return &workspace, nil
+}
+
+// move a workspace to another plan
+func (r *WorkspaceRepositoryImpl) UpdatePlan(ctx context.Context, id uint, plan string) error {
+	result := r.db.WithContext(ctx).Model(&model.Workspace{}).Where("id = ?", id).Update("plan", plan)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("workspace %d not found", id)
+	}
+
+	return nil
+}
+
+// find all workspaces a user is a member of
+func (r *WorkspaceRepositoryImpl) FindAllByUser(ctx context.Context, userID string) ([]model.Workspace, error) {
+	var workspaces []model.Workspace
+	err := r.db.WithContext(ctx).
+		Joins("JOIN memberships ON memberships.workspace_id = workspaces.id").
+		Where("memberships.user_id = ?", userID).
+		Order("workspaces.name").
+		Find(&workspaces).Error
+	if err != nil {
+		return nil, fmt.Errorf("error finding workspaces: %w", err)
+	}
+
+	return workspaces, nil
+}
+
+// find the membership of a user in a workspace
+func (r *WorkspaceRepositoryImpl) FindMembership(ctx context.Context, workspaceID uint, userID string) (*model.Membership, error) {
+	var membership model.Membership
+	err := r.db.WithContext(ctx).Where("workspace_id = ? AND user_id = ?", workspaceID, userID).First(&membership).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("user %s is not a member of workspace %d", userID, workspaceID)
+		}
+		return nil, fmt.Errorf("error finding membership: %w", err)
+	}
+
+	return &membership, nil
+}
+
+// find all members of a workspace
+func (r *WorkspaceRepositoryImpl) FindMembers(ctx context.Context, workspaceID uint) ([]model.Membership, error) {
+	var members []model.Membership
+	if err := r.db.WithContext(ctx).Where("workspace_id = ?", workspaceID).Order("created_at").Find(&members).Error; err != nil {
+		return nil, fmt.Errorf("error finding members: %w", err)
+	}
+
+	return members, nil
+}
+
+// count the members of a workspace having a role
+func (r *WorkspaceRepositoryImpl) CountMembersWithRole(ctx context.Context, workspaceID uint, role model.Role) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.Membership{}).Where("workspace_id = ? AND role = ?", workspaceID, role).Count(&count).Error
+
+	return count, err
+}
+
+// create a new membership
+func (r *WorkspaceRepositoryImpl) CreateMembership(ctx context.Context, membership *model.Membership) error {
+	return r.db.WithContext(ctx).Create(membership).Error
+}
+
+// update a membership
+func (r *WorkspaceRepositoryImpl) UpdateMembership(ctx context.Context, membership *model.Membership) error {
+	return r.db.WithContext(ctx).Save(membership).Error
+}
+
+// delete a membership
+func (r *WorkspaceRepositoryImpl) DeleteMembership(ctx context.Context, membership *model.Membership) error {
+	return r.db.WithContext(ctx).Delete(membership).Error
+}
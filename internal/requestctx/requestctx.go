@@ -0,0 +1,78 @@
+package requestctx
+
+import (
+	"context"
+
+	"url_shortener/internal/model"
+)
+
+type contextKey int
+
+const (
+	userIDKey contextKey = iota
+	workspaceIDKey
+	roleKey
+	requestIDKey
+	clientIPKey
+	operatorKey
+)
+
+// WithRequest returns a copy of ctx carrying the request ID and the client IP
+func WithRequest(ctx context.Context, requestID, clientIP string) context.Context {
+	ctx = context.WithValue(ctx, requestIDKey, requestID)
+	return context.WithValue(ctx, clientIPKey, clientIP)
+}
+
+// RequestID returns the ID of the request, empty outside of a request
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// ClientIP returns the IP of the client, empty outside of a request
+func ClientIP(ctx context.Context) string {
+	clientIP, _ := ctx.Value(clientIPKey).(string)
+	return clientIP
+}
+
+// WithUserID returns a copy of ctx carrying the authenticated user ID
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDKey, userID)
+}
+
+// UserID returns the authenticated user ID, empty for anonymous requests
+func UserID(ctx context.Context) string {
+	userID, _ := ctx.Value(userIDKey).(string)
+	return userID
+}
+
+// WithOperator returns a copy of ctx marking the authenticated user as an
+// operator of the service, allowed to moderate links of any workspace
+func WithOperator(ctx context.Context) context.Context {
+	return context.WithValue(ctx, operatorKey, true)
+}
+
+// IsOperator reports whether the authenticated user operates the service
+func IsOperator(ctx context.Context) bool {
+	operator, _ := ctx.Value(operatorKey).(bool)
+	return operator
+}
+
+// WithWorkspace returns a copy of ctx carrying the active workspace and the
+// role of the user in it
+func WithWorkspace(ctx context.Context, workspaceID uint, role model.Role) context.Context {
+	ctx = context.WithValue(ctx, workspaceIDKey, workspaceID)
+	return context.WithValue(ctx, roleKey, role)
+}
+
+// WorkspaceID returns the active workspace ID, zero when none is resolved
+func WorkspaceID(ctx context.Context) uint {
+	workspaceID, _ := ctx.Value(workspaceIDKey).(uint)
+	return workspaceID
+}
+
+// Role returns the role of the user in the active workspace
+func Role(ctx context.Context) model.Role {
+	role, _ := ctx.Value(roleKey).(model.Role)
+	return role
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"url_shortener/internal/model"
+	"url_shortener/internal/repository"
+	"url_shortener/internal/requestctx"
+)
+
+// DefaultAbuseReportPageSize is the number of reports returned when no limit is given
+const DefaultAbuseReportPageSize = 50
+
+// interface for abuse reporting and moderation operations
+type AbuseService interface {
+	Report(ctx context.Context, domain *model.Domain, shortCode string, req model.CreateAbuseReportRequest, ip string) (*model.AbuseReport, error)
+	ListReports(ctx context.Context, req model.ListAbuseReportsRequest) (*model.ListAbuseReportsResponse, error)
+	Resolve(ctx context.Context, id uint, req model.ResolveAbuseReportRequest) (*model.AbuseReport, error)
+}
+
+// implements AbuseService interface
+type AbuseServiceImpl struct {
+	abuseRepo    repository.AbuseReportRepository
+	urlRepo      repository.URLRepository
+	urlService   URLService
+	auditService AuditService
+}
+
+// create a new abuse service
+func NewAbuseService(abuseRepo repository.AbuseReportRepository, urlRepo repository.URLRepository, urlService URLService, auditService AuditService) AbuseService {
+	return &AbuseServiceImpl{
+		abuseRepo:    abuseRepo,
+		urlRepo:      urlRepo,
+		urlService:   urlService,
+		auditService: auditService,
+	}
+}
+
+// Report files an abuse report against a link, queued for the operators of
+// the service. Reports of whitelisted links are resolved right away
+func (s *AbuseServiceImpl) Report(ctx context.Context, domain *model.Domain, shortCode string, req model.CreateAbuseReportRequest, ip string) (*model.AbuseReport, error) {
+	url, err := s.urlRepo.FindByShortCode(ctx, domain.ID, shortCode)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &model.AbuseReport{
+		WorkspaceID:   url.WorkspaceID,
+		URLID:         url.ID,
+		DomainID:      url.DomainID,
+		ShortCode:     url.ShortCode,
+		Category:      req.Category,
+		Details:       req.Details,
+		ReporterEmail: req.Email,
+		ReporterIP:    ip,
+		Status:        model.AbuseReportOpen,
+	}
+	if url.Whitelisted {
+		now := time.Now()
+		report.Status = model.AbuseReportResolved
+		report.Action = model.AbuseActionWhitelist
+		report.ResolutionNote = "link is whitelisted"
+		report.ResolvedBy = model.AuditActorSystem
+		report.ResolvedAt = &now
+	}
+
+	if err := s.abuseRepo.Create(ctx, report); err != nil {
+		return nil, fmt.Errorf("failed to create abuse report: %w", err)
+	}
+
+	log.Printf("abuse report %d filed against URL %d as %s", report.ID, url.ID, report.Category)
+
+	return report, nil
+}
+
+// ListReports lists the abuse reports of every workspace, oldest first. Only
+// operators of the service see the queue
+func (s *AbuseServiceImpl) ListReports(ctx context.Context, req model.ListAbuseReportsRequest) (*model.ListAbuseReportsResponse, error) {
+	if !requestctx.IsOperator(ctx) {
+		return nil, fmt.Errorf("%w: moderation requires a service operator", ErrForbidden)
+	}
+	if req.Limit <= 0 {
+		req.Limit = DefaultAbuseReportPageSize
+	}
+
+	reports, total, err := s.abuseRepo.Find(ctx, req.Status, req.Limit, req.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ListAbuseReportsResponse{
+		Reports: reports,
+		Total:   total,
+		Limit:   req.Limit,
+		Offset:  req.Offset,
+	}, nil
+}
+
+// Resolve applies a moderation decision to the link of an open report,
+// resolving every open report of the link with it. Only operators of the
+// service moderate, so that the owners of a link cannot clear it themselves
+func (s *AbuseServiceImpl) Resolve(ctx context.Context, id uint, req model.ResolveAbuseReportRequest) (*model.AbuseReport, error) {
+	if !requestctx.IsOperator(ctx) {
+		return nil, fmt.Errorf("%w: moderation requires a service operator", ErrForbidden)
+	}
+
+	report, err := s.abuseRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if report.Status != model.AbuseReportOpen {
+		return nil, fmt.Errorf("abuse report %d is already resolved", id)
+	}
+
+	url, err := s.urlRepo.FindByShortCode(ctx, report.DomainID, report.ShortCode)
+	if err != nil || url.ID != report.URLID {
+		// the link is already gone, only the reports are left to close
+		if req.Action != model.AbuseActionDismiss && req.Action != model.AbuseActionDelete {
+			return nil, fmt.Errorf("reported link no longer exists")
+		}
+	} else if err := s.urlService.ModerateURL(ctx, url, req.Action, req.Reason); err != nil {
+		return nil, err
+	}
+
+	before := *report
+	now := time.Now()
+	report.Status = model.AbuseReportResolved
+	report.Action = req.Action
+	report.ResolutionNote = req.Reason
+	report.ResolvedBy = requestctx.UserID(ctx)
+	report.ResolvedAt = &now
+
+	if _, err := s.abuseRepo.ResolveOpen(ctx, report.URLID, report); err != nil {
+		return nil, err
+	}
+
+	s.auditService.Record(ctx, model.AuditEvent{
+		WorkspaceID: report.WorkspaceID,
+		Action:      model.AuditAbuseReportResolved,
+		TargetType:  model.AuditTargetAbuseReport,
+		TargetID:    strconv.FormatUint(uint64(report.ID), 10),
+	}, &before, report)
+
+	return report, nil
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"reflect"
+
+	"url_shortener/internal/model"
+	"url_shortener/internal/repository"
+	"url_shortener/internal/requestctx"
+)
+
+// DefaultAuditPageSize is the number of audit events returned when no limit is given
+const DefaultAuditPageSize = 50
+
+// auditIgnoredFields never show up in the before/after diff of an audit event
+var auditIgnoredFields = map[string]bool{"updated_at": true}
+
+// interface for audit log operations
+type AuditService interface {
+	Record(ctx context.Context, event model.AuditEvent, before, after interface{})
+	Query(ctx context.Context, query model.AuditQuery) (*model.ListAuditEventsResponse, error)
+	Export(ctx context.Context, query model.AuditQuery, w io.Writer) error
+}
+
+// implements AuditService interface
+type AuditServiceImpl struct {
+	auditRepo repository.AuditRepository
+}
+
+// create a new audit service
+func NewAuditService(auditRepo repository.AuditRepository) AuditService {
+	return &AuditServiceImpl{
+		auditRepo: auditRepo,
+	}
+}
+
+// Record appends an action to the audit log. The actor, workspace, client IP
+// and request ID default to the ones of the request, and only the fields that
+// differ between before and after are kept. Failures are logged, never
+// returned, as the audited action already happened
+func (s *AuditServiceImpl) Record(ctx context.Context, event model.AuditEvent, before, after interface{}) {
+	if event.WorkspaceID == 0 {
+		event.WorkspaceID = requestctx.WorkspaceID(ctx)
+	}
+	if event.ActorID == "" {
+		event.ActorID = requestctx.UserID(ctx)
+	}
+	if event.ActorID == "" {
+		event.ActorID = model.AuditActorSystem
+	}
+	if event.IP == "" {
+		event.IP = requestctx.ClientIP(ctx)
+	}
+	if event.RequestID == "" {
+		event.RequestID = requestctx.RequestID(ctx)
+	}
+
+	var err error
+	event.Before, event.After, err = auditDiff(before, after)
+	if err != nil {
+		log.Printf("error diffing audit event %s on %s %s: %v", event.Action, event.TargetType, event.TargetID, err)
+	}
+
+	if err := s.auditRepo.Create(ctx, &event); err != nil {
+		log.Printf("error recording audit event %s on %s %s: %v", event.Action, event.TargetType, event.TargetID, err)
+	}
+}
+
+// Query lists the audit events of the active workspace matching a query, newest first
+func (s *AuditServiceImpl) Query(ctx context.Context, query model.AuditQuery) (*model.ListAuditEventsResponse, error) {
+	if query.Limit <= 0 {
+		query.Limit = DefaultAuditPageSize
+	}
+
+	events, total, err := s.auditRepo.Find(ctx, requestctx.WorkspaceID(ctx), query)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ListAuditEventsResponse{
+		Events: events,
+		Total:  total,
+		Limit:  query.Limit,
+		Offset: query.Offset,
+	}, nil
+}
+
+// Export writes every audit event of the active workspace matching a query to
+// w as newline-delimited JSON, oldest first, ignoring pagination
+func (s *AuditServiceImpl) Export(ctx context.Context, query model.AuditQuery, w io.Writer) error {
+	encoder := json.NewEncoder(w)
+
+	return s.auditRepo.Each(ctx, requestctx.WorkspaceID(ctx), query, func(event *model.AuditEvent) error {
+		return encoder.Encode(event)
+	})
+}
+
+// auditDiff returns the JSON of the fields that differ between before and
+// after, a nil side meaning the target was created or deleted
+func auditDiff(before, after interface{}) (model.JSON, model.JSON, error) {
+	beforeFields, err := auditFields(before)
+	if err != nil {
+		return nil, nil, err
+	}
+	afterFields, err := auditFields(after)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if beforeFields != nil && afterFields != nil {
+		for field, value := range beforeFields {
+			if reflect.DeepEqual(value, afterFields[field]) {
+				delete(beforeFields, field)
+				delete(afterFields, field)
+			}
+		}
+	}
+
+	beforeJSON, err := marshalAuditFields(beforeFields)
+	if err != nil {
+		return nil, nil, err
+	}
+	afterJSON, err := marshalAuditFields(afterFields)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return beforeJSON, afterJSON, nil
+}
+
+// auditFields converts a value to its JSON fields, nil for a nil value
+func auditFields(value interface{}) (map[string]interface{}, error) {
+	if value == nil || (reflect.ValueOf(value).Kind() == reflect.Ptr && reflect.ValueOf(value).IsNil()) {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal audit target: %w", err)
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal audit target: %w", err)
+	}
+
+	for field := range auditIgnoredFields {
+		delete(fields, field)
+	}
+
+	return fields, nil
+}
+
+// marshalAuditFields converts fields back to JSON, nil when there are none
+func marshalAuditFields(fields map[string]interface{}) (model.JSON, error) {
+	if fields == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(fields)
+}
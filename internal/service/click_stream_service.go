@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	neturl "net/url"
+	"time"
+
+	"url_shortener/internal/model"
+	"url_shortener/pkg/cache"
+	"url_shortener/pkg/useragent"
+)
+
+// ClickChannelPrefix prefixes the Redis pub/sub channels click events are published on
+const ClickChannelPrefix = "clicks:"
+
+// interface for live click stream operations
+type ClickStreamService interface {
+	Publish(ctx context.Context, url *model.URL, visit model.VisitInfo, at time.Time)
+	Subscribe(ctx context.Context, workspaceID, linkID uint) (<-chan model.ClickEvent, func() error, error)
+}
+
+// implements ClickStreamService interface over Redis pub/sub, so that any
+// replica can stream the clicks served by the others
+type ClickStreamServiceImpl struct {
+	cache *cache.RedisClient
+}
+
+// create a new click stream service
+func NewClickStreamService(cache *cache.RedisClient) ClickStreamService {
+	return &ClickStreamServiceImpl{
+		cache: cache,
+	}
+}
+
+// Publish broadcasts a visit to the watchers of its link and of its workspace
+func (s *ClickStreamServiceImpl) Publish(ctx context.Context, url *model.URL, visit model.VisitInfo, at time.Time) {
+	event := model.ClickEvent{
+		LinkID:    url.ID,
+		ShortCode: url.ShortCode,
+		Timestamp: at.UTC(),
+		Country:   visit.Country,
+		Device:    useragent.Device(visit.UserAgent),
+		Source:    visit.Source,
+	}
+	if referer, err := neturl.Parse(visit.Referer); err == nil {
+		event.RefererHost = referer.Hostname()
+	}
+
+	message, err := json.Marshal(event)
+	if err != nil {
+		fmt.Printf("Error encoding click event: %v\n", err)
+		return
+	}
+
+	for _, channel := range []string{linkClickChannel(url.ID), workspaceClickChannel(url.WorkspaceID)} {
+		if err := s.cache.Publish(ctx, channel, message); err != nil {
+			fmt.Printf("Error publishing click event: %v\n", err)
+		}
+	}
+}
+
+// Subscribe streams the clicks of a link, or of a whole workspace when linkID is
+// zero, until ctx is done or the returned function is called
+func (s *ClickStreamServiceImpl) Subscribe(ctx context.Context, workspaceID, linkID uint) (<-chan model.ClickEvent, func() error, error) {
+	channel := workspaceClickChannel(workspaceID)
+	if linkID != 0 {
+		channel = linkClickChannel(linkID)
+	}
+
+	messages, unsubscribe, err := s.cache.Subscribe(ctx, channel)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	clicks := make(chan model.ClickEvent)
+	go func() {
+		defer close(clicks)
+		for message := range messages {
+			var event model.ClickEvent
+			if err := json.Unmarshal([]byte(message), &event); err != nil {
+				continue
+			}
+
+			select {
+			case clicks <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return clicks, unsubscribe, nil
+}
+
+// linkClickChannel returns the channel of the clicks of a link
+func linkClickChannel(linkID uint) string {
+	return fmt.Sprintf("%slink:%d", ClickChannelPrefix, linkID)
+}
+
+// workspaceClickChannel returns the channel of the clicks of a workspace
+func workspaceClickChannel(workspaceID uint) string {
+	return fmt.Sprintf("%sworkspace:%d", ClickChannelPrefix, workspaceID)
+}
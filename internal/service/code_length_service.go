@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+
+	"url_shortener/internal/repository"
+	"url_shortener/pkg/cache"
+	shortener "url_shortener/pkg/shotener"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// CodeLengthKey is the redis key holding the length of generated codes shared by all replicas
+const CodeLengthKey = "short_code_length"
+
+var (
+	shortCodeLength = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "short_code_length",
+			Help: "Length of the generated short codes",
+		},
+	)
+	shortCodeCollisionsTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "short_code_collisions_total",
+			Help: "Total number of generated short codes already in use",
+		},
+	)
+	shortCodeKeyspaceUtilization = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "short_code_keyspace_utilization",
+			Help: "Share of the codes of each length already taken",
+		},
+		[]string{"length"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(shortCodeLength, shortCodeCollisionsTotal, shortCodeKeyspaceUtilization)
+}
+
+// interface for the adaptive length of generated codes
+type CodeLengthService interface {
+	RecordCollisions(ctx context.Context, generated, collisions int)
+	Sync(ctx context.Context) error
+	ReportUtilization(ctx context.Context) error
+}
+
+// implements CodeLengthService interface
+type CodeLengthServiceImpl struct {
+	urlRepo    repository.URLRepository
+	cache      *cache.RedisClient
+	shortener  *shortener.Shortener
+	minLength  int
+	maxLength  int
+	threshold  float64
+	minSamples int
+
+	mu         sync.Mutex
+	generated  int
+	collisions int
+}
+
+// create a new code length service growing the codes of shortener by one
+// character, up to maxLength, whenever more than threshold of the codes
+// generated over a window of minSamples codes collided
+func NewCodeLengthService(urlRepo repository.URLRepository, cache *cache.RedisClient, shortener *shortener.Shortener, maxLength int, threshold float64, minSamples int) CodeLengthService {
+	shortCodeLength.Set(float64(shortener.Length()))
+	return &CodeLengthServiceImpl{
+		urlRepo:    urlRepo,
+		cache:      cache,
+		shortener:  shortener,
+		minLength:  shortener.Length(),
+		maxLength:  maxLength,
+		threshold:  threshold,
+		minSamples: minSamples,
+	}
+}
+
+// RecordCollisions counts generated codes and the ones already in use, and
+// grows the code length once the collision rate passes the threshold
+func (s *CodeLengthServiceImpl) RecordCollisions(ctx context.Context, generated, collisions int) {
+	shortCodeCollisionsTotal.Add(float64(collisions))
+
+	s.mu.Lock()
+	s.generated += generated
+	s.collisions += collisions
+	if s.generated < s.minSamples {
+		s.mu.Unlock()
+		return
+	}
+	rate := float64(s.collisions) / float64(s.generated)
+	s.generated, s.collisions = 0, 0
+	s.mu.Unlock()
+
+	if rate <= s.threshold {
+		return
+	}
+
+	length := s.shortener.Length()
+	if length >= s.maxLength {
+		log.Printf("Short code collision rate %.2f at the maximum length %d", rate, length)
+		return
+	}
+
+	// the shared length only ever grows, so concurrent replicas agree on it
+	shared, err := s.cache.SetMax(ctx, CodeLengthKey, int64(length+1))
+	if err != nil {
+		log.Printf("Error growing short code length: %v", err)
+		return
+	}
+	log.Printf("Short code collision rate %.2f, growing codes to %d characters", rate, shared)
+	s.apply(int(shared))
+}
+
+// Sync applies the code length shared by the replicas
+func (s *CodeLengthServiceImpl) Sync(ctx context.Context) error {
+	value, err := s.cache.Get(ctx, CodeLengthKey)
+	if errors.Is(err, cache.ErrNotFound) {
+		// no replica grew the codes yet
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	length, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("invalid shared code length %q", value)
+	}
+	s.apply(length)
+
+	return nil
+}
+
+// ReportUtilization updates the share of the keyspace of each length in use
+func (s *CodeLengthServiceImpl) ReportUtilization(ctx context.Context) error {
+	counts, err := s.urlRepo.CountByCodeLength(ctx)
+	if err != nil {
+		return err
+	}
+
+	for length, count := range counts {
+		size := s.shortener.KeyspaceSize(length)
+		shortCodeKeyspaceUtilization.WithLabelValues(strconv.Itoa(length)).Set(float64(count) / float64(size))
+	}
+
+	return nil
+}
+
+// apply sets the length of generated codes, never below the configured one
+func (s *CodeLengthServiceImpl) apply(length int) {
+	length = min(max(length, s.minLength), s.maxLength)
+	if s.shortener.SetLength(length) {
+		shortCodeLength.Set(float64(length))
+	}
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"url_shortener/pkg/cache"
+	shortener "url_shortener/pkg/shotener"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func TestSyncKeepsLengthOnRedisOutage(t *testing.T) {
+	server := miniredis.RunT(t)
+	client, err := cache.NewRedisClient(server.Addr(), "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	codes := shortener.NewShortener(6, nil, nil)
+	s := NewCodeLengthService(nil, client, codes, 10, 0.1, 100)
+	ctx := context.Background()
+
+	// no replica grew the codes yet
+	if err := s.Sync(ctx); err != nil {
+		t.Fatalf("Sync without a shared length: %v", err)
+	}
+
+	server.Set(CodeLengthKey, "8")
+	if err := s.Sync(ctx); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if length := codes.Length(); length != 8 {
+		t.Fatalf("length = %d, want the shared length 8", length)
+	}
+
+	server.Close()
+	if err := s.Sync(ctx); err == nil {
+		t.Error("Sync succeeded with redis unreachable")
+	}
+	if length := codes.Length(); length != 8 {
+		t.Errorf("length = %d after a redis outage, want 8 kept", length)
+	}
+}
@@ -0,0 +1,378 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"url_shortener/internal/model"
+	"url_shortener/internal/repository"
+	"url_shortener/internal/requestctx"
+	"url_shortener/pkg/cache"
+	"url_shortener/pkg/dns"
+)
+
+const (
+	DomainCacheTTL       = 10 * time.Minute
+	DomainCacheKeyPrefix = "domain:"
+)
+
+// interface for domain operations
+type DomainService interface {
+	EnsureDefaultDomain(ctx context.Context, baseURL string) (*model.Domain, error)
+	CreateDomain(ctx context.Context, req model.CreateDomainRequest) (*model.Domain, error)
+	ListDomains(ctx context.Context) ([]model.Domain, error)
+	GetDomain(ctx context.Context, host string) (*model.Domain, error)
+	GetDomainByID(ctx context.Context, id uint) (*model.Domain, error)
+	ResolveHost(ctx context.Context, host string) (*model.Domain, error)
+	GetVerification(ctx context.Context, id uint) (*model.DomainVerificationResponse, error)
+	VerifyDomain(ctx context.Context, id uint) (*model.Domain, error)
+	ReverifyDomains(ctx context.Context) (int, error)
+}
+
+// implements DomainService interface
+type DomainServiceImpl struct {
+	domainRepo   repository.DomainRepository
+	resolver     dns.Resolver
+	auditService AuditService
+	cache        *cache.RedisClient
+}
+
+// create a new domain service
+func NewDomainService(domainRepo repository.DomainRepository, resolver dns.Resolver, auditService AuditService, cache *cache.RedisClient) DomainService {
+	return &DomainServiceImpl{
+		domainRepo:   domainRepo,
+		resolver:     resolver,
+		auditService: auditService,
+		cache:        cache,
+	}
+}
+
+// EnsureDefaultDomain registers the configured short URL domain as the default
+// domain and attaches links created before domains existed to it
+func (s *DomainServiceImpl) EnsureDefaultDomain(ctx context.Context, baseURL string) (*model.Domain, error) {
+	parsed, err := neturl.Parse(baseURL)
+	if err != nil || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid short url domain %q", baseURL)
+	}
+	host := model.NormalizeHost(parsed.Host)
+
+	domain, err := s.domainRepo.FindByHost(ctx, host)
+	if err != nil {
+		domain = &model.Domain{Host: host}
+	}
+
+	if current, err := s.domainRepo.FindDefault(ctx); err == nil && current.ID != domain.ID {
+		current.IsDefault = false
+		if err := s.domainRepo.Update(ctx, current); err != nil {
+			return nil, fmt.Errorf("failed to update domain: %w", err)
+		}
+		s.invalidate(ctx, current)
+	}
+
+	// the default domain is owned by the deployment and needs no DNS proof
+	now := time.Now()
+	domain.Scheme = parsed.Scheme
+	domain.IsDefault = true
+	domain.Status = model.DomainStatusVerified
+	if domain.VerifiedAt == nil {
+		domain.VerifiedAt = &now
+	}
+	if err := s.domainRepo.Update(ctx, domain); err != nil {
+		return nil, fmt.Errorf("failed to save default domain: %w", err)
+	}
+	s.invalidate(ctx, domain)
+
+	count, err := s.domainRepo.AssignURLsWithoutDomain(ctx, domain.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assign urls to default domain: %w", err)
+	}
+	if count > 0 {
+		log.Printf("assigned %d URLs to default domain %s", count, domain.Host)
+	}
+
+	return domain, nil
+}
+
+// CreateDomain adds a short domain to the active workspace
+func (s *DomainServiceImpl) CreateDomain(ctx context.Context, req model.CreateDomainRequest) (*model.Domain, error) {
+	host := model.NormalizeHost(req.Host)
+	if _, err := s.domainRepo.FindByHost(ctx, host); err == nil {
+		return nil, fmt.Errorf("domain %s already exists", host)
+	}
+
+	scheme := req.Scheme
+	if scheme == "" {
+		scheme = "https"
+	}
+
+	token, err := generateVerificationToken()
+	if err != nil {
+		return nil, err
+	}
+
+	workspaceID := requestctx.WorkspaceID(ctx)
+	domain := &model.Domain{
+		WorkspaceID:       &workspaceID,
+		Host:              host,
+		Scheme:            scheme,
+		Status:            model.DomainStatusPending,
+		VerificationToken: token,
+	}
+
+	if err := s.domainRepo.Create(ctx, domain); err != nil {
+		return nil, fmt.Errorf("failed to create domain: %w", err)
+	}
+
+	s.auditService.Record(ctx, domainAuditEvent(model.AuditDomainCreated, domain), nil, domain)
+
+	return domain, nil
+}
+
+// ListDomains lists the short domains usable by the active workspace
+func (s *DomainServiceImpl) ListDomains(ctx context.Context) ([]model.Domain, error) {
+	return s.domainRepo.FindAllForWorkspace(ctx, requestctx.WorkspaceID(ctx))
+}
+
+// GetDomain gets a domain by host, or the default domain when host is empty
+func (s *DomainServiceImpl) GetDomain(ctx context.Context, host string) (*model.Domain, error) {
+	if host == "" {
+		return s.cached(ctx, DomainCacheKeyPrefix+"default", func() (*model.Domain, error) {
+			return s.domainRepo.FindDefault(ctx)
+		})
+	}
+
+	host = model.NormalizeHost(host)
+	return s.cached(ctx, DomainCacheKeyPrefix+"host:"+host, func() (*model.Domain, error) {
+		return s.domainRepo.FindByHost(ctx, host)
+	})
+}
+
+// GetDomainByID gets a domain by id
+func (s *DomainServiceImpl) GetDomainByID(ctx context.Context, id uint) (*model.Domain, error) {
+	return s.cached(ctx, fmt.Sprintf("%sid:%d", DomainCacheKeyPrefix, id), func() (*model.Domain, error) {
+		return s.domainRepo.FindByID(ctx, id)
+	})
+}
+
+// ResolveHost gets the domain a request was made to from its Host header,
+// falling back to the default domain for hosts that are not registered.
+// Registered domains are only served once their ownership is verified
+func (s *DomainServiceImpl) ResolveHost(ctx context.Context, host string) (*model.Domain, error) {
+	if host != "" {
+		if domain, err := s.GetDomain(ctx, host); err == nil {
+			if !domain.IsVerified() {
+				return nil, fmt.Errorf("domain %s is not verified", domain.Host)
+			}
+			return domain, nil
+		}
+	}
+
+	return s.GetDomain(ctx, "")
+}
+
+// GetVerification tells which TXT record proves ownership of a domain
+func (s *DomainServiceImpl) GetVerification(ctx context.Context, id uint) (*model.DomainVerificationResponse, error) {
+	domain, err := s.findWorkspaceDomain(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// domains added before verification existed have no token yet
+	if domain.VerificationToken == "" && !domain.IsDefault {
+		if domain.VerificationToken, err = generateVerificationToken(); err != nil {
+			return nil, err
+		}
+		if err := s.domainRepo.Update(ctx, domain); err != nil {
+			return nil, fmt.Errorf("failed to update domain: %w", err)
+		}
+	}
+
+	return &model.DomainVerificationResponse{
+		Domain:      domain.Host,
+		Status:      domain.Status,
+		RecordType:  "TXT",
+		RecordName:  domain.VerificationRecordName(),
+		RecordValue: domain.VerificationToken,
+	}, nil
+}
+
+// VerifyDomain checks the ownership TXT record of a domain right away
+func (s *DomainServiceImpl) VerifyDomain(ctx context.Context, id uint) (*model.Domain, error) {
+	domain, err := s.findWorkspaceDomain(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkDomain(ctx, domain); err != nil {
+		return nil, err
+	}
+
+	return domain, nil
+}
+
+// findWorkspaceDomain finds a domain owned by the active workspace
+func (s *DomainServiceImpl) findWorkspaceDomain(ctx context.Context, id uint) (*model.Domain, error) {
+	domain, err := s.domainRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if domain.WorkspaceID == nil || *domain.WorkspaceID != requestctx.WorkspaceID(ctx) {
+		return nil, fmt.Errorf("domain %d not found", id)
+	}
+
+	return domain, nil
+}
+
+// ReverifyDomains re-checks the ownership of every custom domain, disabling the
+// ones that lost their TXT record, and returns the number of disabled domains
+func (s *DomainServiceImpl) ReverifyDomains(ctx context.Context) (int, error) {
+	domains, err := s.domainRepo.FindAll(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	disabled := 0
+	for i := range domains {
+		domain := &domains[i]
+		if domain.IsDefault {
+			continue
+		}
+
+		wasVerified := domain.IsVerified()
+		if err := s.checkDomain(ctx, domain); err != nil {
+			log.Printf("error verifying domain %s: %v", domain.Host, err)
+			continue
+		}
+
+		if wasVerified && !domain.IsVerified() {
+			disabled++
+		}
+	}
+
+	return disabled, nil
+}
+
+// checkDomain looks up the ownership TXT record of a domain and updates its status.
+// Failed lookups leave the status untouched so that DNS outages don't disable domains
+func (s *DomainServiceImpl) checkDomain(ctx context.Context, domain *model.Domain) error {
+	if domain.IsDefault {
+		return nil
+	}
+
+	records, err := s.resolver.LookupTXT(ctx, domain.VerificationRecordName())
+	if err != nil {
+		return err
+	}
+
+	before := *domain
+	now := time.Now()
+	domain.LastCheckedAt = &now
+
+	switch {
+	case hasToken(records, domain.VerificationToken):
+		if !domain.IsVerified() {
+			domain.VerifiedAt = &now
+		}
+		domain.Status = model.DomainStatusVerified
+	case domain.IsVerified():
+		log.Printf("domain %s lost its verification record, disabling it", domain.Host)
+		domain.Status = model.DomainStatusDisabled
+	}
+
+	if err := s.domainRepo.Update(ctx, domain); err != nil {
+		return fmt.Errorf("failed to update domain: %w", err)
+	}
+	s.invalidate(ctx, domain)
+
+	if domain.Status != before.Status {
+		action := model.AuditDomainVerified
+		if domain.Status == model.DomainStatusDisabled {
+			action = model.AuditDomainDisabled
+		}
+		s.auditService.Record(ctx, domainAuditEvent(action, domain), &before, domain)
+	}
+
+	return nil
+}
+
+// domainAuditEvent returns the audit event of an action on a domain
+func domainAuditEvent(action model.AuditAction, domain *model.Domain) model.AuditEvent {
+	event := model.AuditEvent{
+		Action:     action,
+		TargetType: model.AuditTargetDomain,
+		TargetID:   strconv.FormatUint(uint64(domain.ID), 10),
+	}
+	if domain.WorkspaceID != nil {
+		event.WorkspaceID = *domain.WorkspaceID
+	}
+
+	return event
+}
+
+// hasToken reports whether one of the TXT records holds the verification token
+func hasToken(records []string, token string) bool {
+	if token == "" {
+		return false
+	}
+
+	for _, record := range records {
+		if strings.Trim(strings.TrimSpace(record), `"`) == token {
+			return true
+		}
+	}
+
+	return false
+}
+
+// generateVerificationToken creates a random domain verification token
+func generateVerificationToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate verification token: %w", err)
+	}
+
+	return "shortener-verify=" + hex.EncodeToString(b), nil
+}
+
+// cached loads a domain through the cache
+func (s *DomainServiceImpl) cached(ctx context.Context, key string, load func() (*model.Domain, error)) (*model.Domain, error) {
+	var domain model.Domain
+	if err := s.cache.GetObject(ctx, key, &domain); err == nil {
+		return &domain, nil
+	}
+
+	loaded, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.cache.SetWithTTL(ctx, key, loaded, DomainCacheTTL); err != nil {
+		// Log error but continue; this is not critical
+		fmt.Printf("Error caching domain: %v\n", err)
+	}
+
+	return loaded, nil
+}
+
+// invalidate removes every cache entry of a domain
+func (s *DomainServiceImpl) invalidate(ctx context.Context, domain *model.Domain) {
+	keys := []string{
+		DomainCacheKeyPrefix + "default",
+		DomainCacheKeyPrefix + "host:" + domain.Host,
+		fmt.Sprintf("%sid:%d", DomainCacheKeyPrefix, domain.ID),
+	}
+
+	for _, key := range keys {
+		if err := s.cache.Delete(ctx, key); err != nil {
+			fmt.Printf("Error invalidating domain cache: %v\n", err)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"url_shortener/internal/model"
+	"url_shortener/internal/requestctx"
+	"url_shortener/pkg/cache"
+	"url_shortener/pkg/dns"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+// fakeDomainRepository keeps domains in memory, by id
+type fakeDomainRepository struct {
+	domains map[uint]model.Domain
+}
+
+func (r *fakeDomainRepository) FindByID(ctx context.Context, id uint) (*model.Domain, error) {
+	domain, ok := r.domains[id]
+	if !ok {
+		return nil, fmt.Errorf("domain %d not found", id)
+	}
+
+	return &domain, nil
+}
+
+func (r *fakeDomainRepository) FindByHost(ctx context.Context, host string) (*model.Domain, error) {
+	for _, domain := range r.domains {
+		if domain.Host == host {
+			return &domain, nil
+		}
+	}
+
+	return nil, fmt.Errorf("domain %s not found", host)
+}
+
+func (r *fakeDomainRepository) FindDefault(ctx context.Context) (*model.Domain, error) {
+	for _, domain := range r.domains {
+		if domain.IsDefault {
+			return &domain, nil
+		}
+	}
+
+	return nil, errors.New("no default domain")
+}
+
+func (r *fakeDomainRepository) FindAll(ctx context.Context) ([]model.Domain, error) {
+	var domains []model.Domain
+	for id := uint(1); id <= uint(len(r.domains)); id++ {
+		domains = append(domains, r.domains[id])
+	}
+
+	return domains, nil
+}
+
+func (r *fakeDomainRepository) Update(ctx context.Context, domain *model.Domain) error {
+	r.domains[domain.ID] = *domain
+	return nil
+}
+
+func (r *fakeDomainRepository) Create(ctx context.Context, domain *model.Domain) error {
+	domain.ID = uint(len(r.domains) + 1)
+	r.domains[domain.ID] = *domain
+	return nil
+}
+
+func (r *fakeDomainRepository) FindAllForWorkspace(ctx context.Context, workspaceID uint) ([]model.Domain, error) {
+	return nil, nil
+}
+
+func (r *fakeDomainRepository) AssignURLsWithoutDomain(ctx context.Context, domainID uint) (int64, error) {
+	return 0, nil
+}
+
+// fakeAuditService keeps the actions recorded
+type fakeAuditService struct {
+	AuditService
+	actions []model.AuditAction
+}
+
+func (s *fakeAuditService) Record(ctx context.Context, event model.AuditEvent, before, after interface{}) {
+	s.actions = append(s.actions, event.Action)
+}
+
+// newTestCache returns a cache backed by an in-memory redis server
+func newTestCache(t *testing.T) *cache.RedisClient {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client, err := cache.NewRedisClient(server.Addr(), "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	return client
+}
+
+const testWorkspaceID = 7
+
+// newDomainFixture returns a domain service over a pending custom domain of
+// the test workspace, a verified one and the default domain
+func newDomainFixture(t *testing.T) (*DomainServiceImpl, *fakeDomainRepository, *dns.MemoryResolver, *fakeAuditService) {
+	t.Helper()
+
+	workspaceID := uint(testWorkspaceID)
+	verifiedAt := time.Now().Add(-24 * time.Hour)
+	repo := &fakeDomainRepository{domains: map[uint]model.Domain{
+		1: {ID: 1, WorkspaceID: &workspaceID, Host: "go.example.com", Status: model.DomainStatusPending, VerificationToken: "shortener-verify=pending"},
+		2: {ID: 2, WorkspaceID: &workspaceID, Host: "links.example.org", Status: model.DomainStatusVerified, VerificationToken: "shortener-verify=verified", VerifiedAt: &verifiedAt},
+		3: {ID: 3, Host: "sho.rt", IsDefault: true, Status: model.DomainStatusVerified},
+	}}
+	resolver := dns.NewMemoryResolver()
+	audit := &fakeAuditService{}
+
+	s := NewDomainService(repo, resolver, audit, newTestCache(t)).(*DomainServiceImpl)
+
+	return s, repo, resolver, audit
+}
+
+// workspaceContext returns a context acting as an admin of the test workspace
+func workspaceContext() context.Context {
+	return requestctx.WithWorkspace(context.Background(), testWorkspaceID, model.RoleAdmin)
+}
+
+func TestVerifyDomainWithRecord(t *testing.T) {
+	s, repo, resolver, audit := newDomainFixture(t)
+	resolver.SetTXT("_shortener-verify.go.example.com", "unrelated", ` "shortener-verify=pending" `)
+
+	domain, err := s.VerifyDomain(workspaceContext(), 1)
+	if err != nil {
+		t.Fatalf("VerifyDomain: %v", err)
+	}
+
+	if domain.Status != model.DomainStatusVerified || domain.VerifiedAt == nil || domain.LastCheckedAt == nil {
+		t.Errorf("domain = %+v, want it verified and checked", domain)
+	}
+	if repo.domains[1].Status != model.DomainStatusVerified {
+		t.Errorf("stored status = %s, want verified", repo.domains[1].Status)
+	}
+	if len(audit.actions) != 1 || audit.actions[0] != model.AuditDomainVerified {
+		t.Errorf("audit actions = %v, want [%s]", audit.actions, model.AuditDomainVerified)
+	}
+}
+
+func TestVerifyDomainWithoutRecord(t *testing.T) {
+	s, _, resolver, audit := newDomainFixture(t)
+	resolver.SetTXT("_shortener-verify.go.example.com", "shortener-verify=someone-else")
+
+	domain, err := s.VerifyDomain(workspaceContext(), 1)
+	if err != nil {
+		t.Fatalf("VerifyDomain: %v", err)
+	}
+
+	if domain.Status != model.DomainStatusPending || domain.VerifiedAt != nil {
+		t.Errorf("domain = %+v, want it still pending", domain)
+	}
+	if len(audit.actions) != 0 {
+		t.Errorf("audit actions = %v, want none", audit.actions)
+	}
+}
+
+func TestVerifyDomainOfOtherWorkspace(t *testing.T) {
+	s, _, resolver, _ := newDomainFixture(t)
+	ctx := requestctx.WithWorkspace(context.Background(), testWorkspaceID+1, model.RoleOwner)
+
+	if _, err := s.VerifyDomain(ctx, 1); err == nil {
+		t.Fatal("VerifyDomain succeeded on the domain of another workspace")
+	}
+	if resolver.Lookups() != 0 {
+		t.Errorf("resolver looked up %d names, want none", resolver.Lookups())
+	}
+}
+
+func TestVerifyDomainInvalidatesCache(t *testing.T) {
+	s, _, resolver, _ := newDomainFixture(t)
+	ctx := workspaceContext()
+
+	// the pending domain is cached before its verification
+	if domain, err := s.GetDomain(ctx, "go.example.com"); err != nil || domain.IsVerified() {
+		t.Fatalf("GetDomain = %+v, %v, want the pending domain", domain, err)
+	}
+
+	resolver.SetTXT("_shortener-verify.go.example.com", "shortener-verify=pending")
+	if _, err := s.VerifyDomain(ctx, 1); err != nil {
+		t.Fatalf("VerifyDomain: %v", err)
+	}
+
+	domain, err := s.ResolveHost(ctx, "go.example.com")
+	if err != nil {
+		t.Fatalf("ResolveHost: %v", err)
+	}
+	if domain.ID != 1 {
+		t.Errorf("ResolveHost = domain %d, want the verified domain 1", domain.ID)
+	}
+}
+
+func TestReverifyDomainsDisablesLostRecords(t *testing.T) {
+	s, repo, resolver, audit := newDomainFixture(t)
+	resolver.SetTXT("_shortener-verify.go.example.com", "shortener-verify=pending")
+
+	disabled, err := s.ReverifyDomains(context.Background())
+	if err != nil {
+		t.Fatalf("ReverifyDomains: %v", err)
+	}
+
+	if disabled != 1 {
+		t.Errorf("ReverifyDomains = %d, want 1", disabled)
+	}
+	if status := repo.domains[1].Status; status != model.DomainStatusVerified {
+		t.Errorf("domain 1 status = %s, want verified", status)
+	}
+	if status := repo.domains[2].Status; status != model.DomainStatusDisabled {
+		t.Errorf("domain 2 status = %s, want disabled", status)
+	}
+	if status := repo.domains[3].Status; status != model.DomainStatusVerified {
+		t.Errorf("default domain status = %s, want verified", status)
+	}
+	// the default domain is never looked up
+	if resolver.Lookups() != 2 {
+		t.Errorf("resolver looked up %d names, want 2", resolver.Lookups())
+	}
+	if len(audit.actions) != 2 {
+		t.Errorf("audit actions = %v, want a verification and a disabling", audit.actions)
+	}
+}
+
+func TestReverifyDomainsKeepsStatusOnLookupFailure(t *testing.T) {
+	s, repo, resolver, audit := newDomainFixture(t)
+	resolver.Fail(errors.New("dns outage"))
+
+	disabled, err := s.ReverifyDomains(context.Background())
+	if err != nil {
+		t.Fatalf("ReverifyDomains: %v", err)
+	}
+
+	if disabled != 0 {
+		t.Errorf("ReverifyDomains = %d, want 0", disabled)
+	}
+	if status := repo.domains[2].Status; status != model.DomainStatusVerified {
+		t.Errorf("domain 2 status = %s, want it still verified", status)
+	}
+	if repo.domains[2].LastCheckedAt != nil {
+		t.Error("domain 2 was marked as checked by a failed lookup")
+	}
+	if len(audit.actions) != 0 {
+		t.Errorf("audit actions = %v, want none", audit.actions)
+	}
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrForbidden is returned when the caller's role does not allow an operation
+	ErrForbidden = errors.New("forbidden")
+	// ErrQuotaExceeded is returned when a workspace went over a limit of its plan
+	ErrQuotaExceeded = errors.New("quota exceeded")
+	// ErrUnknownPlan is returned when a workspace is moved to a plan that is not configured
+	ErrUnknownPlan = errors.New("unknown plan")
+	// ErrUnsafeURL is returned when a destination URL fails the safety checks
+	ErrUnsafeURL = errors.New("unsafe url")
+	// ErrRedirectLoop is returned when a destination URL is a short URL, of this
+	// service or of a shortener chain longer than the hop limit
+	ErrRedirectLoop = errors.New("destination is a short url")
+	// ErrExpansionFailed is returned when a third-party shortener could not be
+	// expanded, the shortener failing or being unreachable
+	ErrExpansionFailed = errors.New("failed to expand short url")
+	// ErrInvalidCustomCode is returned when a custom code is malformed or
+	// rejected by the code policy
+	ErrInvalidCustomCode = errors.New("invalid custom code")
+	// ErrCodeTaken is returned when a custom code is already in use on its domain
+	ErrCodeTaken = errors.New("custom code already in use")
+)
+
+// CodeTakenError is returned when a custom code is already in use, along with
+// available codes to use instead
+type CodeTakenError struct {
+	Code        string
+	Suggestions []string
+}
+
+func (e *CodeTakenError) Error() string {
+	return fmt.Sprintf("custom code %s already in use", e.Code)
+}
+
+// Unwrap makes the error match ErrCodeTaken
+func (e *CodeTakenError) Unwrap() error {
+	return ErrCodeTaken
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"url_shortener/internal/repository"
+	shortener "url_shortener/pkg/shotener"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// KeyPoolInsertBatch is the number of generated codes inserted into the pool at once
+const KeyPoolInsertBatch = 1000
+
+// ErrKeyPoolEmpty is returned when the key pool has no unclaimed code left
+var ErrKeyPoolEmpty = errors.New("key pool is empty")
+
+var (
+	keyPoolDepth = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "key_pool_depth",
+			Help: "Number of unclaimed short codes in the key pool",
+		},
+	)
+	keyPoolLocalDepth = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "key_pool_local_depth",
+			Help: "Number of short codes claimed by this replica and not handed out yet",
+		},
+	)
+	keyPoolGeneratedTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "key_pool_generated_total",
+			Help: "Total number of short codes added to the key pool",
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(keyPoolDepth, keyPoolLocalDepth, keyPoolGeneratedTotal)
+}
+
+// interface for pre-generated short code operations
+type KeyPoolService interface {
+	Take(ctx context.Context) (string, error)
+	Reserve(ctx context.Context, code string) error
+	Refill(ctx context.Context) (int64, error)
+}
+
+// implements KeyPoolService interface
+type KeyPoolServiceImpl struct {
+	repo       repository.KeyPoolRepository
+	generator  shortener.CodeGenerator
+	lengths    CodeLengthService
+	claimBatch int
+	lowWater   int64
+	target     int64
+
+	mu      sync.Mutex
+	claimed []string
+}
+
+// create a new key pool service filling the pool with codes of generator up
+// to target whenever it falls below lowWater, reporting the generated codes
+// already in use to lengths. Codes are claimed claimBatch at a time by each
+// replica
+func NewKeyPoolService(repo repository.KeyPoolRepository, generator shortener.CodeGenerator, lengths CodeLengthService, claimBatch int, lowWater, target int64) KeyPoolService {
+	return &KeyPoolServiceImpl{
+		repo:       repo,
+		generator:  generator,
+		lengths:    lengths,
+		claimBatch: claimBatch,
+		lowWater:   lowWater,
+		target:     target,
+	}
+}
+
+// Take hands out an unused short code, claiming a new batch from the pool
+// when the codes claimed by this replica ran out
+func (s *KeyPoolServiceImpl) Take(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.claimed) == 0 {
+		codes, err := s.repo.Claim(ctx, s.claimBatch)
+		if err != nil {
+			return "", err
+		}
+		if len(codes) == 0 {
+			return "", ErrKeyPoolEmpty
+		}
+		s.claimed = codes
+	}
+
+	code := s.claimed[len(s.claimed)-1]
+	s.claimed = s.claimed[:len(s.claimed)-1]
+	keyPoolLocalDepth.Set(float64(len(s.claimed)))
+
+	return code, nil
+}
+
+// Reserve keeps the pool from handing out a code taken as a custom code
+func (s *KeyPoolServiceImpl) Reserve(ctx context.Context, code string) error {
+	return s.repo.Reserve(ctx, code)
+}
+
+// Refill tops the pool up to its target when it fell below the low-water
+// mark, and returns the number of codes added. A single replica fills the
+// pool at a time, the others skip their turn
+func (s *KeyPoolServiceImpl) Refill(ctx context.Context) (int64, error) {
+	unlock, locked, err := s.repo.TryLock(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if !locked {
+		return 0, nil
+	}
+	defer unlock()
+
+	depth, err := s.repo.CountAvailable(ctx)
+	if err != nil {
+		return 0, err
+	}
+	keyPoolDepth.Set(float64(depth))
+
+	if depth >= s.lowWater {
+		return 0, nil
+	}
+
+	var added int64
+	for depth < s.target {
+		n := min(s.target-depth, KeyPoolInsertBatch)
+		codes := make([]string, 0, n)
+		for i := int64(0); i < n; i++ {
+			code, err := s.generator.Generate(ctx)
+			if err != nil {
+				return added, fmt.Errorf("failed to generate short code: %w", err)
+			}
+			codes = append(codes, code)
+		}
+
+		inserted, err := s.repo.Insert(ctx, codes)
+		if err != nil {
+			return added, err
+		}
+		s.lengths.RecordCollisions(ctx, len(codes), len(codes)-int(inserted))
+		// a batch made only of codes in use means the keyspace is close to full
+		if inserted == 0 {
+			return added, shortener.ErrKeyspaceExhausted
+		}
+
+		added += inserted
+		depth += inserted
+		keyPoolGeneratedTotal.Add(float64(inserted))
+		keyPoolDepth.Set(float64(depth))
+	}
+
+	return added, nil
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"log"
+	neturl "net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"url_shortener/internal/model"
+	"url_shortener/internal/repository"
+	"url_shortener/internal/requestctx"
+	"url_shortener/pkg/linkcheck"
+)
+
+const (
+	// LinkCheckBatchSize is the number of links loaded at once while checking
+	LinkCheckBatchSize = 500
+	// DefaultBrokenLinksPageSize is the number of broken links returned when no limit is given
+	DefaultBrokenLinksPageSize = 50
+)
+
+// interface for link health operations
+type LinkHealthService interface {
+	CheckLinks(ctx context.Context) (int, int, error)
+	GetHealth(ctx context.Context, urlID uint) (*model.LinkHealth, error)
+	ListBroken(ctx context.Context, limit, offset int) (*model.ListBrokenLinksResponse, error)
+}
+
+// implements LinkHealthService interface
+type LinkHealthServiceImpl struct {
+	healthRepo       repository.LinkHealthRepository
+	urlRepo          repository.URLRepository
+	checker          *linkcheck.Checker
+	concurrency      int
+	hostDelay        time.Duration
+	failureThreshold int
+}
+
+// create a new link health service checking at most concurrency hosts at
+// once, waiting hostDelay between requests to the same host, and flagging
+// links broken after failureThreshold failed checks in a row
+func NewLinkHealthService(healthRepo repository.LinkHealthRepository, urlRepo repository.URLRepository, checker *linkcheck.Checker, concurrency int, hostDelay time.Duration, failureThreshold int) LinkHealthService {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	return &LinkHealthServiceImpl{
+		healthRepo:       healthRepo,
+		urlRepo:          urlRepo,
+		checker:          checker,
+		concurrency:      concurrency,
+		hostDelay:        hostDelay,
+		failureThreshold: failureThreshold,
+	}
+}
+
+// CheckLinks checks the destination of every active link and returns the
+// number of links checked and the number found broken
+func (s *LinkHealthServiceImpl) CheckLinks(ctx context.Context) (int, int, error) {
+	var checked, broken int
+	pacer := &hostPacer{delay: s.hostDelay, last: make(map[string]time.Time)}
+	err := s.urlRepo.EachActive(ctx, LinkCheckBatchSize, func(urls []model.URL) error {
+		ids := make([]uint, len(urls))
+		for i := range urls {
+			ids[i] = urls[i].ID
+		}
+
+		previous, err := s.healthRepo.FindByURLIDs(ctx, ids)
+		if err != nil {
+			return err
+		}
+
+		for _, health := range s.checkBatch(ctx, urls, previous, pacer) {
+			checked++
+			if health.Broken {
+				broken++
+			}
+		}
+
+		return ctx.Err()
+	})
+
+	return checked, broken, err
+}
+
+// checkBatch checks a batch of links, one host at a time per worker so that
+// no host receives concurrent requests, and stores their health. Requests to a
+// host are spaced by pacer, which spans the whole run
+func (s *LinkHealthServiceImpl) checkBatch(ctx context.Context, urls []model.URL, previous map[uint]model.LinkHealth, pacer *hostPacer) []model.LinkHealth {
+	byHost := make(map[string][]*model.URL)
+	for i := range urls {
+		host := destinationHost(urls[i].OriginalURL)
+		byHost[host] = append(byHost[host], &urls[i])
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		results []model.LinkHealth
+	)
+	slots := make(chan struct{}, s.concurrency)
+
+	for host, hostURLs := range byHost {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(host string, hostURLs []*model.URL) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			for _, url := range hostURLs {
+				if err := pacer.wait(ctx, host); err != nil {
+					return
+				}
+
+				health := s.record(ctx, url, previous[url.ID], s.checker.Check(ctx, url.OriginalURL))
+				mu.Lock()
+				results = append(results, health)
+				mu.Unlock()
+			}
+		}(host, hostURLs)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// hostPacer spaces the requests to every host by delay
+type hostPacer struct {
+	delay time.Duration
+	mu    sync.Mutex
+	last  map[string]time.Time
+}
+
+// wait blocks until host may be requested again, and records the request
+func (p *hostPacer) wait(ctx context.Context, host string) error {
+	p.mu.Lock()
+	last, seen := p.last[host]
+	p.mu.Unlock()
+
+	if seen {
+		if delay := time.Until(last.Add(p.delay)); delay > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+	}
+
+	p.mu.Lock()
+	p.last[host] = time.Now()
+	p.mu.Unlock()
+
+	return nil
+}
+
+// record stores the outcome of a check, counting consecutive failures
+func (s *LinkHealthServiceImpl) record(ctx context.Context, url *model.URL, previous model.LinkHealth, result linkcheck.Result) model.LinkHealth {
+	now := time.Now()
+	health := model.LinkHealth{
+		URLID:       url.ID,
+		WorkspaceID: url.WorkspaceID,
+		StatusCode:  result.StatusCode,
+		LatencyMs:   result.Latency.Milliseconds(),
+		FinalURL:    result.FinalURL,
+		CheckedAt:   now,
+	}
+
+	if !result.OK() {
+		health.ConsecutiveFailures = previous.ConsecutiveFailures + 1
+		if result.Err != nil {
+			health.LastError = result.Err.Error()
+		}
+	}
+
+	health.Broken = health.ConsecutiveFailures >= s.failureThreshold
+	if health.Broken {
+		health.BrokenSince = previous.BrokenSince
+		if health.BrokenSince == nil {
+			health.BrokenSince = &now
+		}
+	}
+
+	if err := s.healthRepo.Upsert(ctx, &health); err != nil {
+		log.Printf("error recording health of URL %d: %v", url.ID, err)
+	}
+
+	return health
+}
+
+// GetHealth gets the latest health check of a link
+func (s *LinkHealthServiceImpl) GetHealth(ctx context.Context, urlID uint) (*model.LinkHealth, error) {
+	return s.healthRepo.FindByURLID(ctx, urlID)
+}
+
+// ListBroken lists the broken links of the active workspace, longest broken first
+func (s *LinkHealthServiceImpl) ListBroken(ctx context.Context, limit, offset int) (*model.ListBrokenLinksResponse, error) {
+	if limit <= 0 {
+		limit = DefaultBrokenLinksPageSize
+	}
+
+	links, total, err := s.healthRepo.FindBroken(ctx, requestctx.WorkspaceID(ctx), limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ListBrokenLinksResponse{
+		Links:  links,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}, nil
+}
+
+// destinationHost returns the host a destination URL is fetched from
+func destinationHost(rawURL string) string {
+	u, err := neturl.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+
+	return strings.ToLower(u.Hostname())
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"url_shortener/internal/model"
+	"url_shortener/internal/repository"
+	"url_shortener/pkg/linkcheck"
+)
+
+// fakeBatchURLRepository hands out its active links in fixed batches
+type fakeBatchURLRepository struct {
+	repository.URLRepository
+	batches [][]model.URL
+}
+
+func (r *fakeBatchURLRepository) EachActive(ctx context.Context, batchSize int, fn func(urls []model.URL) error) error {
+	for _, batch := range r.batches {
+		if err := fn(batch); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fakeLinkHealthRepository keeps the last health stored of every link
+type fakeLinkHealthRepository struct {
+	repository.LinkHealthRepository
+	mu     sync.Mutex
+	health map[uint]model.LinkHealth
+}
+
+func (r *fakeLinkHealthRepository) FindByURLIDs(ctx context.Context, urlIDs []uint) (map[uint]model.LinkHealth, error) {
+	return map[uint]model.LinkHealth{}, nil
+}
+
+func (r *fakeLinkHealthRepository) Upsert(ctx context.Context, health *model.LinkHealth) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.health[health.URLID] = *health
+	return nil
+}
+
+func TestCheckLinksPacesHostsAcrossBatches(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []time.Time
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, time.Now())
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	// every batch holds a single link of the same host
+	urlRepo := &fakeBatchURLRepository{}
+	for id := uint(1); id <= 3; id++ {
+		urlRepo.batches = append(urlRepo.batches, []model.URL{{ID: id, OriginalURL: fmt.Sprintf("%s/%d", server.URL, id)}})
+	}
+
+	const hostDelay = 100 * time.Millisecond
+	healthRepo := &fakeLinkHealthRepository{health: map[uint]model.LinkHealth{}}
+	s := NewLinkHealthService(healthRepo, urlRepo, linkcheck.NewChecker(server.Client()), 0, hostDelay, 3)
+
+	checked, broken, err := s.CheckLinks(context.Background())
+	if err != nil {
+		t.Fatalf("CheckLinks: %v", err)
+	}
+	if checked != 3 || broken != 0 {
+		t.Errorf("CheckLinks = %d checked, %d broken, want 3 checked, 0 broken", checked, broken)
+	}
+
+	if len(requests) != 3 {
+		t.Fatalf("server received %d requests, want 3", len(requests))
+	}
+	// requests are paced when sent, allow for them reaching the server unevenly
+	minGap := hostDelay * 8 / 10
+	for i := 1; i < len(requests); i++ {
+		if gap := requests[i].Sub(requests[i-1]); gap < minGap {
+			t.Errorf("requests %d and %d were %v apart, want about %v", i-1, i, gap, hostDelay)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"url_shortener/internal/model"
+	"url_shortener/internal/repository"
+	"url_shortener/pkg/cache"
+	"url_shortener/pkg/metadata"
+)
+
+const (
+	// MetadataFetchTimeout bounds a background metadata fetch
+	MetadataFetchTimeout = 30 * time.Second
+	// MetadataConcurrency is the number of background metadata fetches run at once
+	MetadataConcurrency = 10
+)
+
+// interface for destination metadata operations
+type MetadataService interface {
+	Refresh(ctx context.Context, url *model.URL) (*model.LinkMetadata, error)
+	RefreshAsync(url *model.URL)
+}
+
+// implements MetadataService interface
+type MetadataServiceImpl struct {
+	urlRepo repository.URLRepository
+	fetcher *metadata.Fetcher
+	cache   *cache.RedisClient
+	slots   chan struct{}
+}
+
+// create a new metadata service
+func NewMetadataService(urlRepo repository.URLRepository, fetcher *metadata.Fetcher, cache *cache.RedisClient) MetadataService {
+	return &MetadataServiceImpl{
+		urlRepo: urlRepo,
+		fetcher: fetcher,
+		cache:   cache,
+		slots:   make(chan struct{}, MetadataConcurrency),
+	}
+}
+
+// Refresh fetches the metadata of the destination of a link and stores it on
+// the link. A failed fetch is recorded, keeping the metadata fetched before
+func (s *MetadataServiceImpl) Refresh(ctx context.Context, url *model.URL) (*model.LinkMetadata, error) {
+	now := time.Now()
+	meta := url.Metadata
+	meta.FetchedAt = &now
+
+	fetched, fetchErr := s.fetcher.Fetch(ctx, url.OriginalURL)
+	if fetchErr != nil {
+		meta.FetchError = fetchErr.Error()
+	} else {
+		meta = model.LinkMetadata{
+			Title:       fetched.Title,
+			Description: fetched.Description,
+			FaviconURL:  fetched.FaviconURL,
+			ImageURL:    fetched.ImageURL,
+			FetchedAt:   &now,
+		}
+	}
+
+	if err := s.urlRepo.UpdateMetadata(ctx, url.ID, meta); err != nil {
+		return nil, fmt.Errorf("failed to store metadata: %w", err)
+	}
+	url.Metadata = meta
+
+	if err := s.cache.Delete(ctx, cacheKey(url.DomainID, url.ShortCode)); err != nil {
+		log.Printf("error invalidating URL cache: %v", err)
+	}
+
+	if fetchErr != nil {
+		return nil, fetchErr
+	}
+
+	return &meta, nil
+}
+
+// RefreshAsync refreshes the metadata of a link in background, a limited
+// number of fetches running at once
+func (s *MetadataServiceImpl) RefreshAsync(url *model.URL) {
+	target := *url
+
+	go func() {
+		s.slots <- struct{}{}
+		defer func() { <-s.slots }()
+
+		ctx, cancel := context.WithTimeout(context.Background(), MetadataFetchTimeout)
+		defer cancel()
+
+		if _, err := s.Refresh(ctx, &target); err != nil {
+			log.Printf("error fetching metadata of URL %d: %v", target.ID, err)
+		}
+	}()
+}
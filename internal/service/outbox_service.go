@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"url_shortener/internal/model"
+	"url_shortener/internal/repository"
+	"url_shortener/pkg/events"
+)
+
+// OutboxBatchSize is the number of outbox events published at once
+const OutboxBatchSize = 100
+
+// OutboxHandler reacts to relayed outbox events. Handlers run before the events
+// are marked as published, so the events of a failing handler are relayed again,
+// to every handler: handlers must be idempotent
+type OutboxHandler func(ctx context.Context, events []model.OutboxEvent) error
+
+// interface for outbox relay operations
+type OutboxService interface {
+	Relay(ctx context.Context) (int, error)
+	Purge(ctx context.Context, retention time.Duration) (int64, error)
+}
+
+// implements OutboxService interface
+type OutboxServiceImpl struct {
+	outboxRepo repository.OutboxRepository
+	publisher  events.EventPublisher
+	handlers   []OutboxHandler
+}
+
+// create a new outbox service relaying events to publisher, then to handlers
+func NewOutboxService(outboxRepo repository.OutboxRepository, publisher events.EventPublisher, handlers ...OutboxHandler) OutboxService {
+	return &OutboxServiceImpl{
+		outboxRepo: outboxRepo,
+		publisher:  publisher,
+		handlers:   handlers,
+	}
+}
+
+// Relay publishes the pending outbox events in order, batch by batch, and
+// returns the number of events published
+func (s *OutboxServiceImpl) Relay(ctx context.Context) (int, error) {
+	published := 0
+	for {
+		count, err := s.outboxRepo.ProcessPending(ctx, OutboxBatchSize, func(pending []model.OutboxEvent) error {
+			if err := s.publisher.Publish(ctx, toEvents(pending)...); err != nil {
+				return err
+			}
+
+			for _, handle := range s.handlers {
+				if err := handle(ctx, pending); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+		published += count
+		if err != nil || count < OutboxBatchSize {
+			return published, err
+		}
+	}
+}
+
+// Purge deletes the events published longer than retention ago
+func (s *OutboxServiceImpl) Purge(ctx context.Context, retention time.Duration) (int64, error) {
+	return s.outboxRepo.DeletePublishedBefore(ctx, time.Now().Add(-retention))
+}
+
+// toEvents converts outbox events to published events, identified by their outbox ID
+func toEvents(pending []model.OutboxEvent) []events.Event {
+	published := make([]events.Event, len(pending))
+	for i, event := range pending {
+		published[i] = events.Event{
+			ID:            strconv.FormatUint(uint64(event.ID), 10),
+			Type:          event.EventType,
+			AggregateType: event.AggregateType,
+			AggregateID:   event.AggregateID,
+			Payload:       json.RawMessage(event.Payload),
+			OccurredAt:    event.CreatedAt,
+		}
+	}
+
+	return published
+}
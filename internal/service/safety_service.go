@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	neturl "net/url"
+
+	"url_shortener/internal/requestctx"
+	"url_shortener/pkg/safety"
+	"url_shortener/pkg/unshorten"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var urlSafetyBlockedTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "url_safety_blocked_total",
+		Help: "Total number of destination URLs blocked by the safety checks",
+	},
+	[]string{"reason"},
+)
+
+func init() {
+	prometheus.MustRegister(urlSafetyBlockedTotal)
+}
+
+// interface for destination URL safety checks
+type SafetyService interface {
+	ResolveDestination(ctx context.Context, rawURL string) (string, error)
+	CheckURL(ctx context.Context, rawURL string) error
+	Scan(ctx context.Context, urls []string) (map[string]safety.Verdict, error)
+}
+
+// implements SafetyService interface
+type SafetyServiceImpl struct {
+	checker       *safety.Checker
+	reputation    safety.ReputationProvider
+	expander      *unshorten.Expander
+	domainService DomainService
+}
+
+// create a new safety service checking destinations against the local lists
+// of checker, then with the reputation provider unless it is nil. Third-party
+// shortener URLs are expanded when expander is not nil
+func NewSafetyService(checker *safety.Checker, reputation safety.ReputationProvider, expander *unshorten.Expander, domainService DomainService) SafetyService {
+	return &SafetyServiceImpl{
+		checker:       checker,
+		reputation:    reputation,
+		expander:      expander,
+		domainService: domainService,
+	}
+}
+
+// ResolveDestination returns the URL a link to rawURL should be saved with:
+// the URL hidden behind third-party shorteners when expanding them, rejected
+// with ErrRedirectLoop when it is one of our own short URLs or a shortener
+// chain too long to follow, and with ErrUnsafeURL when it fails the safety
+// checks. Shorteners failing to answer are reported with ErrExpansionFailed
+func (s *SafetyServiceImpl) ResolveDestination(ctx context.Context, rawURL string) (string, error) {
+	destination := rawURL
+	if s.expander != nil && s.expander.IsShortener(rawURL) {
+		expanded, err := s.expander.Expand(ctx, rawURL)
+		if errors.Is(err, unshorten.ErrTooManyHops) {
+			return "", fmt.Errorf("%w: %v", ErrRedirectLoop, err)
+		}
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", ErrExpansionFailed, err)
+		}
+		destination = expanded
+	}
+
+	if err := s.checkLoop(ctx, destination); err != nil {
+		return "", err
+	}
+
+	if err := s.CheckURL(ctx, destination); err != nil {
+		return "", err
+	}
+
+	return destination, nil
+}
+
+// checkLoop rejects destinations on any of the domains links are served from
+func (s *SafetyServiceImpl) checkLoop(ctx context.Context, rawURL string) error {
+	u, err := neturl.Parse(rawURL)
+	if err != nil {
+		return nil
+	}
+
+	for _, host := range []string{u.Host, u.Hostname()} {
+		if host == "" {
+			continue
+		}
+		if _, err := s.domainService.GetDomain(ctx, host); err == nil {
+			return fmt.Errorf("%w: %s is a short domain of this service", ErrRedirectLoop, host)
+		}
+	}
+
+	return nil
+}
+
+// CheckURL returns an error wrapping ErrUnsafeURL when a destination must not
+// be shortened. Blocked attempts are logged and counted by reason. The
+// reputation provider failing does not block a destination
+func (s *SafetyServiceImpl) CheckURL(ctx context.Context, rawURL string) error {
+	if err := s.checker.Check(rawURL); err != nil {
+		reason := safety.ReasonInvalid
+		var blocked *safety.BlockedError
+		if errors.As(err, &blocked) {
+			reason = blocked.Reason
+		}
+
+		return s.block(ctx, rawURL, reason, err)
+	}
+
+	if s.reputation == nil {
+		return nil
+	}
+
+	verdicts, err := s.reputation.Lookup(ctx, []string{rawURL})
+	if err != nil {
+		log.Printf("error looking up reputation of %q: %v", rawURL, err)
+		return nil
+	}
+	if verdict := verdicts[rawURL]; verdict.Unsafe() {
+		return s.block(ctx, rawURL, safety.ReasonReputation, fmt.Errorf("url is known for %s", verdict.Threat))
+	}
+
+	return nil
+}
+
+// Scan looks up the reputation of destinations already shortened, returning
+// no verdicts when no reputation provider is configured
+func (s *SafetyServiceImpl) Scan(ctx context.Context, urls []string) (map[string]safety.Verdict, error) {
+	if s.reputation == nil || len(urls) == 0 {
+		return map[string]safety.Verdict{}, nil
+	}
+
+	return s.reputation.Lookup(ctx, urls)
+}
+
+// block logs and counts a blocked destination and returns its error
+func (s *SafetyServiceImpl) block(ctx context.Context, rawURL, reason string, err error) error {
+	urlSafetyBlockedTotal.WithLabelValues(reason).Inc()
+	log.Printf("blocked unsafe url %q for user %q in workspace %d from %s: %v",
+		rawURL, requestctx.UserID(ctx), requestctx.WorkspaceID(ctx), requestctx.ClientIP(ctx), err)
+
+	return fmt.Errorf("%w: %v", ErrUnsafeURL, err)
+}
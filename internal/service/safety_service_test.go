@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"url_shortener/internal/model"
+	"url_shortener/pkg/safety"
+	"url_shortener/pkg/unshorten"
+)
+
+// fakeDomainService serves links from a fixed set of hosts
+type fakeDomainService struct {
+	DomainService
+	hosts map[string]bool
+}
+
+func (f *fakeDomainService) GetDomain(ctx context.Context, host string) (*model.Domain, error) {
+	if !f.hosts[host] {
+		return nil, errors.New("domain not found")
+	}
+
+	return &model.Domain{Host: host}, nil
+}
+
+// newSafetyService returns a safety service expanding the URLs of shortener
+// and serving links from sho.rt
+func newSafetyService(t *testing.T, shortener *httptest.Server) SafetyService {
+	t.Helper()
+
+	checker, err := safety.NewChecker("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := url.Parse(shortener.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expander := unshorten.NewExpander([]string{u.Host}, http.DefaultClient, 3)
+
+	return NewSafetyService(checker, nil, expander, &fakeDomainService{hosts: map[string]bool{"sho.rt": true}})
+}
+
+// newRedirectServer starts a shortener redirecting every request to target
+func newRedirectServer(t *testing.T, target func(r *http.Request) string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target(r), http.StatusMovedPermanently)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestResolveDestinationExpandsShortener(t *testing.T) {
+	server := newRedirectServer(t, func(*http.Request) string { return "https://example.com/page" })
+	s := newSafetyService(t, server)
+
+	got, err := s.ResolveDestination(context.Background(), server.URL+"/abc")
+	if err != nil {
+		t.Fatalf("ResolveDestination: %v", err)
+	}
+	if got != "https://example.com/page" {
+		t.Errorf("ResolveDestination = %q, want %q", got, "https://example.com/page")
+	}
+}
+
+func TestResolveDestinationRejectsShortenerLoop(t *testing.T) {
+	var server *httptest.Server
+	server = newRedirectServer(t, func(r *http.Request) string { return server.URL + r.URL.Path })
+	s := newSafetyService(t, server)
+
+	_, err := s.ResolveDestination(context.Background(), server.URL+"/abc")
+	if !errors.Is(err, ErrRedirectLoop) {
+		t.Fatalf("ResolveDestination error = %v, want ErrRedirectLoop", err)
+	}
+}
+
+func TestResolveDestinationRejectsOwnDomain(t *testing.T) {
+	server := newRedirectServer(t, func(*http.Request) string { return "https://sho.rt/xyz" })
+	s := newSafetyService(t, server)
+
+	_, err := s.ResolveDestination(context.Background(), server.URL+"/abc")
+	if !errors.Is(err, ErrRedirectLoop) {
+		t.Fatalf("ResolveDestination error = %v, want ErrRedirectLoop", err)
+	}
+}
+
+func TestResolveDestinationReportsUnreachableShortener(t *testing.T) {
+	server := newRedirectServer(t, func(*http.Request) string { return "https://example.com/page" })
+	s := newSafetyService(t, server)
+	server.Close()
+
+	_, err := s.ResolveDestination(context.Background(), server.URL+"/abc")
+	if !errors.Is(err, ErrExpansionFailed) {
+		t.Fatalf("ResolveDestination error = %v, want ErrExpansionFailed", err)
+	}
+	if errors.Is(err, ErrRedirectLoop) {
+		t.Errorf("ResolveDestination error = %v, must not be ErrRedirectLoop", err)
+	}
+}
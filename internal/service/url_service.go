@@ -2,109 +2,269 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"log"
+	neturl "net/url"
+	"strconv"
 	"time"
 
 	"url_shortener/internal/model"
 	"url_shortener/internal/repository"
+	"url_shortener/internal/requestctx"
 	"url_shortener/pkg/cache"
+	"url_shortener/pkg/qrcode"
 	shortener "url_shortener/pkg/shotener"
+	"url_shortener/pkg/urlnorm"
 )
 
 const (
-	DefaultCacheTTL = 24 * time.Hour
-	CacheKeyPrefix  = "url:"
+	DefaultCacheTTL  = 24 * time.Hour
+	CacheKeyPrefix   = "url:"
+	QRCacheKeyPrefix = "qr:"
+
+	// QRSourceParam marks visits arriving through the URL encoded in QR codes
+	QRSourceParam = "src"
+
+	// RescanBatchSize is the number of links looked up at once when rescanning destinations
+	RescanBatchSize = 500
+
+	// DefaultSuggestions is the number of codes suggested in place of a taken custom code
+	DefaultSuggestions = 5
+
+	// MaxCorrections is the number of links suggested for a mistyped code
+	MaxCorrections = 3
 )
 
 // interface for URL service operations
 type URLService interface {
 	CreateShortURL(ctx context.Context, req model.CreateURLRequest, ip string) (*model.CreateURLResponse, error)
-	GetOriginalURL(ctx context.Context, shortCode string) (string, error)
-	RecordVisit(ctx context.Context, urlID uint, ip, userAgent, referer string) error
-	GetURLStats(ctx context.Context, shortCode string) (*model.GetURLStatsResponse, error)
+	ResolveURL(ctx context.Context, domain *model.Domain, shortCode string) (*model.URL, error)
+	FindURL(ctx context.Context, domain *model.Domain, shortCode string) (*model.URL, error)
+	ListURLs(ctx context.Context, limit, offset int) (*model.ListURLsResponse, error)
+	UpdateURL(ctx context.Context, domain *model.Domain, shortCode string, req model.UpdateURLRequest) (*model.URL, error)
+	DeleteURL(ctx context.Context, domain *model.Domain, shortCode string) error
+	GetOriginalURL(ctx context.Context, domain *model.Domain, shortCode string) (string, error)
+	RecordVisit(ctx context.Context, url *model.URL, visit model.VisitInfo) error
+	GetURLStats(ctx context.Context, domain *model.Domain, shortCode string) (*model.GetURLStatsResponse, error)
+	SuggestCodes(ctx context.Context, domain *model.Domain, base string, limit int) ([]string, error)
+	SuggestCorrections(ctx context.Context, domain *model.Domain, shortCode string) ([]string, error)
+	GetURLPreview(ctx context.Context, url *model.URL) (*model.URLPreviewResponse, error)
+	RefreshMetadata(ctx context.Context, url *model.URL) (*model.LinkMetadata, error)
+	GetQRCode(ctx context.Context, url *model.URL, format string, opts qrcode.Options) ([]byte, error)
+	GetCampaignStats(ctx context.Context) ([]model.CampaignStats, error)
 	CleanupExpiredURLs(ctx context.Context) (int64, error)
+	RescanURLs(ctx context.Context) (int, error)
+	ModerateURL(ctx context.Context, url *model.URL, action model.AbuseAction, reason string) error
+	HandleLinkEvents(ctx context.Context, events []model.OutboxEvent) error
 }
 
 // implements URLService interface
 type URLServiceImpl struct {
-	urlRepo    repository.URLRepository
-	cache      *cache.RedisClient
-	shortener  *shortener.Shortener
-	domainName string
+	urlRepo       repository.URLRepository
+	utmRepo       repository.UTMTemplateRepository
+	healthRepo    repository.LinkHealthRepository
+	domainService DomainService
+	usageService  UsageService
+	safetyService SafetyService
+	metadata      MetadataService
+	auditService  AuditService
+	webhooks      WebhookService
+	clicks        ClickStreamService
+	cache         *cache.RedisClient
+	shortener     *shortener.Shortener
+	keyPool       KeyPoolService
+	codeLengths   CodeLengthService
 }
 
 // create a new URL service
-func NewURLService(urlRepo repository.URLRepository, cache *cache.RedisClient, shortener *shortener.Shortener, domainName string) URLService {
+func NewURLService(urlRepo repository.URLRepository, utmRepo repository.UTMTemplateRepository, healthRepo repository.LinkHealthRepository, domainService DomainService, usageService UsageService, safetyService SafetyService, metadata MetadataService, auditService AuditService, webhooks WebhookService, clicks ClickStreamService, cache *cache.RedisClient, shortener *shortener.Shortener, keyPool KeyPoolService, codeLengths CodeLengthService) URLService {
 	return &URLServiceImpl{
-		urlRepo:    urlRepo,
-		cache:      cache,
-		shortener:  shortener,
-		domainName: domainName,
+		urlRepo:       urlRepo,
+		utmRepo:       utmRepo,
+		healthRepo:    healthRepo,
+		domainService: domainService,
+		usageService:  usageService,
+		safetyService: safetyService,
+		metadata:      metadata,
+		auditService:  auditService,
+		webhooks:      webhooks,
+		clicks:        clicks,
+		cache:         cache,
+		shortener:     shortener,
+		keyPool:       keyPool,
+		codeLengths:   codeLengths,
 	}
 }
 
+// take a code from the key pool, falling back to generating one when the pool
+// is disabled or ran dry. Either way the code is checked against the domain,
+// since a custom code may have taken it since it was pooled
+func (s *URLServiceImpl) generateShortCode(ctx context.Context, domain *model.Domain) (string, error) {
+	if s.keyPool != nil {
+		for i := 0; i < 5; i++ {
+			shortCode, err := s.keyPool.Take(ctx)
+			if err != nil {
+				log.Printf("Error taking code from key pool, generating one: %v", err)
+				break
+			}
+
+			taken, err := s.urlRepo.FindTakenCodes(ctx, domain.ID, []string{shortCode})
+			if err != nil {
+				return "", err
+			}
+			if len(taken) == 0 {
+				return shortCode, nil
+			}
+		}
+	}
+
+	for i := 0; i < 5; i++ {
+		shortCode, err := s.shortener.Generate(ctx)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate short code: %w", err)
+		}
+
+		taken, err := s.urlRepo.FindTakenCodes(ctx, domain.ID, []string{shortCode})
+		if err != nil {
+			return "", err
+		}
+		if len(taken) == 0 {
+			s.codeLengths.RecordCollisions(ctx, i+1, i)
+			return shortCode, nil
+		}
+	}
+	s.codeLengths.RecordCollisions(ctx, 5, 5)
+
+	return "", fmt.Errorf("failed to generate unique short code")
+}
+
 // create a new shortened url
 func (s *URLServiceImpl) CreateShortURL(ctx context.Context, req model.CreateURLRequest, ip string) (*model.CreateURLResponse, error) {
 	var shortCode string
 	var err error
 
+	workspaceID := requestctx.WorkspaceID(ctx)
+
+	req.OriginalURL, err = s.safetyService.ResolveDestination(ctx, req.OriginalURL)
+	if err != nil {
+		return nil, err
+	}
+	destinationHash, err := urlnorm.Hash(req.OriginalURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %w", err)
+	}
+
+	domain, err := s.domainService.GetDomain(ctx, req.Domain)
+	if err != nil {
+		return nil, err
+	}
+	if !domain.UsableBy(workspaceID) {
+		return nil, fmt.Errorf("domain %s not found", domain.Host)
+	}
+	if !domain.IsVerified() {
+		return nil, fmt.Errorf("domain %s is not verified", domain.Host)
+	}
+
+	utm, err := s.resolveUTM(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// a custom code always asks for a new link
+	if req.Dedupe && req.CustomCode == "" {
+		existing, err := s.findDuplicate(ctx, domain, destinationHash, utm, req)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return &model.CreateURLResponse{
+				ShortURL:    shortURLFor(domain, existing.ShortCode),
+				OriginalURL: existing.OriginalURL,
+				ShortCode:   existing.ShortCode,
+				ExpiresAt:   existing.ExpiresAt,
+				CreatedAt:   existing.CreatedAt,
+				Existing:    true,
+			}, nil
+		}
+	}
+
 	if req.CustomCode != "" {
-		if !s.shortener.IsValidCustomCode(req.CustomCode) {
-			return nil, fmt.Errorf("invalid custom code")
+		if err := s.shortener.ValidateCustomCode(req.CustomCode); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCustomCode, err)
 		}
 
-		_, err := s.urlRepo.FindByShortCode(ctx, req.CustomCode)
+		// short codes only need to be unique within their domain, but expired
+		// and deleted links keep holding theirs
+		taken, err := s.urlRepo.FindTakenCodes(ctx, domain.ID, []string{req.CustomCode})
 		if err != nil {
-			return nil, fmt.Errorf("custom code already in use")
+			return nil, err
 		}
-
-		shortCode = req.CustomCode
-	} else {
-		for i := 0; i < 5; i++ {
-			shortCode, err = s.shortener.Generate()
+		if len(taken) > 0 {
+			suggestions, err := s.suggestCodes(ctx, domain, req.CustomCode, DefaultSuggestions)
 			if err != nil {
-				return nil, fmt.Errorf("failed to generate short code: %w", err)
+				log.Printf("Error suggesting codes for %s: %v", req.CustomCode, err)
 			}
+			return nil, &CodeTakenError{Code: req.CustomCode, Suggestions: suggestions}
+		}
 
-			_, err := s.urlRepo.FindByShortCode(ctx, shortCode)
-			if err != nil {
-				break
+		shortCode = req.CustomCode
+		if s.keyPool != nil {
+			if err := s.keyPool.Reserve(ctx, shortCode); err != nil {
+				log.Printf("Error reserving custom code %s: %v", shortCode, err)
 			}
+		}
+	} else {
+		shortCode, err = s.generateShortCode(ctx, domain)
+		if err != nil {
+			return nil, err
+		}
+	}
 
-			if i == 4 {
-				return nil, fmt.Errorf("failed to generate unique short code")
-			}
+	originalURL := req.OriginalURL
+	if !req.UTMOnRedirect && !utm.IsEmpty() {
+		originalURL, err = utm.Apply(originalURL)
+		if err != nil {
+			return nil, err
 		}
 	}
 
 	url := &model.URL{
-		OriginalURL: req.OriginalURL,
-		ShortCode:   shortCode,
-		ExpiresAt:   req.ExpiresAt,
-		CreatedByIP: ip,
+		WorkspaceID:     workspaceID,
+		OriginalURL:     originalURL,
+		DestinationHash: destinationHash,
+		DomainID:        domain.ID,
+		ShortCode:       shortCode,
+		ExpiresAt:       req.ExpiresAt,
+		CreatedByIP:     ip,
+		UTM:             utm,
+		UTMOnRedirect:   req.UTMOnRedirect,
+		PreviewMode:     req.PreviewMode,
 	}
-
-	if err := s.urlRepo.Create(ctx, url); err != nil {
-		return nil, fmt.Errorf("failed to create URL: %w", err)
+	if req.OpenGraph != nil {
+		url.OpenGraph = *req.OpenGraph
 	}
 
-	cacheKey := fmt.Sprintf("%s%s", CacheKeyPrefix, shortCode)
-	cacheTTL := DefaultCacheTTL
-	if url.ExpiresAt != nil {
-		expityTime := time.Until(*url.ExpiresAt)
-		if expityTime > 0 && expityTime < DefaultCacheTTL {
-			cacheTTL = expityTime
-		}
+	// the link is counted before it is created, so that concurrent creations
+	// cannot go over the quota together
+	if err := s.usageService.Reserve(ctx, workspaceID, model.UsageLinks); err != nil {
+		return nil, err
 	}
 
-	if err := s.cache.SetWithTTL(ctx, cacheKey, url.OriginalURL, cacheTTL); err != nil {
-		fmt.Printf("error caching url: %v\n", err)
+	if err := s.urlRepo.Create(ctx, url); err != nil {
+		s.usageService.Release(ctx, workspaceID, model.UsageLinks)
+		return nil, fmt.Errorf("failed to create URL: %w", err)
 	}
 
-	shortURL := fmt.Sprintf("%s/%s", s.domainName, shortCode)
+	s.cacheURL(ctx, url)
+
+	s.auditService.Record(ctx, linkAuditEvent(model.AuditLinkCreated, url), nil, url)
+	s.metadata.RefreshAsync(url)
 
 	response := &model.CreateURLResponse{
-		ShortURL:    shortURL,
+		ShortURL:    shortURLFor(domain, shortCode),
 		OriginalURL: url.OriginalURL,
 		ShortCode:   shortCode,
 		ExpiresAt:   url.ExpiresAt,
@@ -114,20 +274,147 @@ func (s *URLServiceImpl) CreateShortURL(ctx context.Context, req model.CreateURL
 	return response, nil
 }
 
-// GetOriginalURL retrieves the original URL from a short code
-func (s *URLServiceImpl) GetOriginalURL(ctx context.Context, shortCode string) (string, error) {
+// SuggestCodes returns up to limit available custom codes on a domain close
+// to base, base itself first when it is available
+func (s *URLServiceImpl) SuggestCodes(ctx context.Context, domain *model.Domain, base string, limit int) ([]string, error) {
+	if !domain.UsableBy(requestctx.WorkspaceID(ctx)) {
+		return nil, fmt.Errorf("domain %s not found", domain.Host)
+	}
+
+	return s.suggestCodes(ctx, domain, base, limit)
+}
+
+// SuggestCorrections returns the short URLs of the existing links a code with
+// a bad check character may have been mistyped from, nearest first
+func (s *URLServiceImpl) SuggestCorrections(ctx context.Context, domain *model.Domain, shortCode string) ([]string, error) {
+	candidates := s.shortener.Corrections(shortCode)
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	urls, err := s.urlRepo.FindActiveByShortCodes(ctx, domain.ID, candidates)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]bool, len(urls))
+	for _, url := range urls {
+		existing[url.ShortCode] = true
+	}
+
+	var suggestions []string
+	for _, code := range candidates {
+		if existing[code] && len(suggestions) < MaxCorrections {
+			suggestions = append(suggestions, shortURLFor(domain, code))
+		}
+	}
+
+	return suggestions, nil
+}
+
+// suggestCodes returns up to limit variants of base that are valid custom
+// codes and not taken on the domain, checked all at once
+func (s *URLServiceImpl) suggestCodes(ctx context.Context, domain *model.Domain, base string, limit int) ([]string, error) {
+	var candidates []string
+	seen := map[string]bool{}
+	for _, code := range append([]string{base}, shortener.Variants(base, time.Now())...) {
+		if !seen[code] && s.shortener.IsValidCustomCode(code) {
+			seen[code] = true
+			candidates = append(candidates, code)
+		}
+	}
+	if len(candidates) == 0 {
+		return []string{}, nil
+	}
+
+	taken, err := s.urlRepo.FindTakenCodes(ctx, domain.ID, candidates)
+	if err != nil {
+		return nil, err
+	}
+	unavailable := make(map[string]bool, len(taken))
+	for _, code := range taken {
+		unavailable[code] = true
+	}
+
+	suggestions := []string{}
+	for _, code := range candidates {
+		if len(suggestions) == limit {
+			break
+		}
+		if !unavailable[code] {
+			suggestions = append(suggestions, code)
+		}
+	}
+
+	return suggestions, nil
+}
+
+// findDuplicate returns the active link of the workspace on domain with the
+// same normalized destination and options as the request, nil when none
+func (s *URLServiceImpl) findDuplicate(ctx context.Context, domain *model.Domain, destinationHash string, utm model.UTMParams, req model.CreateURLRequest) (*model.URL, error) {
+	candidates, err := s.urlRepo.FindActiveByDestination(ctx, requestctx.WorkspaceID(ctx), domain.ID, destinationHash)
+	if err != nil {
+		return nil, err
+	}
+
+	var openGraph model.OpenGraph
+	if req.OpenGraph != nil {
+		openGraph = *req.OpenGraph
+	}
+
+	for i := range candidates {
+		url := &candidates[i]
+		if url.UTM == utm &&
+			url.UTMOnRedirect == req.UTMOnRedirect &&
+			url.PreviewMode == req.PreviewMode &&
+			url.OpenGraph == openGraph &&
+			sameTime(url.ExpiresAt, req.ExpiresAt) {
+			return url, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// sameTime reports whether two optional times are both unset or equal
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// resolveUTM merges the referenced UTM template with the UTM fields of the request
+func (s *URLServiceImpl) resolveUTM(ctx context.Context, req model.CreateURLRequest) (model.UTMParams, error) {
+	var utm model.UTMParams
+
+	if req.UTMTemplateID != nil {
+		template, err := findAccessibleTemplate(ctx, s.utmRepo, *req.UTMTemplateID)
+		if err != nil {
+			return utm, err
+		}
+		utm = template.UTM
+	}
+
+	if req.UTM != nil {
+		utm = utm.Merge(*req.UTM)
+	}
+
+	return utm, nil
+}
+
+// ResolveURL retrieves the URL entity for a short code on a domain, from cache when possible
+func (s *URLServiceImpl) ResolveURL(ctx context.Context, domain *model.Domain, shortCode string) (*model.URL, error) {
 	// Try to get from cache first
-	cacheKey := fmt.Sprintf("%s%s", CacheKeyPrefix, shortCode)
-	cachedURL, err := s.cache.Get(ctx, cacheKey)
-	if err == nil {
-		// URL found in cache
-		return cachedURL, nil
+	var cached cachedURL
+	if err := s.cache.GetObject(ctx, cacheKey(domain.ID, shortCode), &cached); err == nil {
+		cached.URL.DestinationHash = cached.DestinationHash
+		return &cached.URL, nil
 	}
 
 	// Not in cache, get from database
-	url, err := s.urlRepo.FindByShortCode(ctx, shortCode)
+	url, err := s.urlRepo.FindByShortCode(ctx, domain.ID, shortCode)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// Increment visit count in background
@@ -139,6 +426,150 @@ func (s *URLServiceImpl) GetOriginalURL(ctx context.Context, shortCode string) (
 	}()
 
 	// Cache the URL for future requests
+	s.cacheURL(ctx, url)
+
+	return url, nil
+}
+
+// FindURL finds a URL of the active workspace, bypassing the cache
+func (s *URLServiceImpl) FindURL(ctx context.Context, domain *model.Domain, shortCode string) (*model.URL, error) {
+	url, err := s.urlRepo.FindByShortCode(ctx, domain.ID, shortCode)
+	if err != nil {
+		return nil, err
+	}
+
+	if url.WorkspaceID != requestctx.WorkspaceID(ctx) {
+		return nil, fmt.Errorf("URL with short code %s not found", shortCode)
+	}
+
+	return url, nil
+}
+
+// ListURLs lists the URLs of the active workspace, newest first
+func (s *URLServiceImpl) ListURLs(ctx context.Context, limit, offset int) (*model.ListURLsResponse, error) {
+	urls, total, err := s.urlRepo.FindAllByWorkspace(ctx, requestctx.WorkspaceID(ctx), limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ListURLsResponse{
+		URLs:   urls,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}, nil
+}
+
+// UpdateURL edits a URL of the active workspace
+func (s *URLServiceImpl) UpdateURL(ctx context.Context, domain *model.Domain, shortCode string, req model.UpdateURLRequest) (*model.URL, error) {
+	url, err := s.FindURL(ctx, domain, shortCode)
+	if err != nil {
+		return nil, err
+	}
+	before := *url
+
+	if req.OriginalURL != nil && *req.OriginalURL != url.OriginalURL {
+		destination, err := s.safetyService.ResolveDestination(ctx, *req.OriginalURL)
+		if err != nil {
+			return nil, err
+		}
+		destinationHash, err := urlnorm.Hash(destination)
+		if err != nil {
+			return nil, fmt.Errorf("invalid url: %w", err)
+		}
+		url.OriginalURL = destination
+		url.DestinationHash = destinationHash
+	}
+	if req.ExpiresAt != nil {
+		url.ExpiresAt = req.ExpiresAt
+	}
+	if req.UTMOnRedirect != nil {
+		url.UTMOnRedirect = *req.UTMOnRedirect
+	}
+	if req.PreviewMode != nil {
+		url.PreviewMode = *req.PreviewMode
+	}
+	if req.OpenGraph != nil {
+		url.OpenGraph = *req.OpenGraph
+	}
+	if req.Disabled != nil && *req.Disabled != url.Disabled {
+		// links taken down stay down until moderation lifts the takedown
+		if url.TakenDown() {
+			return nil, fmt.Errorf("%w: link was taken down by %s", ErrForbidden, url.DisabledBy)
+		}
+		url.Disabled = *req.Disabled
+		url.DisabledBy = ""
+		if url.Disabled {
+			url.DisabledBy = model.DisabledByOwner
+		}
+	}
+
+	if err := s.urlRepo.Update(ctx, url); err != nil {
+		return nil, fmt.Errorf("failed to update URL: %w", err)
+	}
+
+	s.evictURL(ctx, url)
+
+	action := model.AuditLinkUpdated
+	if url.Disabled != before.Disabled {
+		action = model.AuditLinkEnabled
+		if url.Disabled {
+			action = model.AuditLinkDisabled
+		}
+	}
+	s.auditService.Record(ctx, linkAuditEvent(action, url), &before, url)
+	if url.OriginalURL != before.OriginalURL {
+		s.metadata.RefreshAsync(url)
+	}
+
+	return url, nil
+}
+
+// DeleteURL deletes a URL of the active workspace
+func (s *URLServiceImpl) DeleteURL(ctx context.Context, domain *model.Domain, shortCode string) error {
+	url, err := s.FindURL(ctx, domain, shortCode)
+	if err != nil {
+		return err
+	}
+
+	return s.deleteURL(ctx, url)
+}
+
+// deleteURL deletes a URL and evicts it from the cache
+func (s *URLServiceImpl) deleteURL(ctx context.Context, url *model.URL) error {
+	if err := s.urlRepo.Delete(ctx, url); err != nil {
+		return fmt.Errorf("failed to delete URL: %w", err)
+	}
+
+	s.evictURL(ctx, url)
+
+	s.auditService.Record(ctx, linkAuditEvent(model.AuditLinkDeleted, url), url, nil)
+
+	return nil
+}
+
+// GetOriginalURL retrieves the destination URL from a short code
+func (s *URLServiceImpl) GetOriginalURL(ctx context.Context, domain *model.Domain, shortCode string) (string, error) {
+	url, err := s.ResolveURL(ctx, domain, shortCode)
+	if err != nil {
+		return "", err
+	}
+	if url.Disabled {
+		return "", fmt.Errorf("URL with short code %s is disabled", shortCode)
+	}
+
+	return url.Destination(), nil
+}
+
+// cachedURL is the cached form of a URL entity, keeping the fields hidden from
+// API responses so that a URL read from cache can be saved back whole
+type cachedURL struct {
+	model.URL
+	DestinationHash string `json:"destination_hash"`
+}
+
+// cacheURL stores the URL entity in cache, never outliving its expiry
+func (s *URLServiceImpl) cacheURL(ctx context.Context, url *model.URL) {
 	cacheTTL := DefaultCacheTTL
 	if url.ExpiresAt != nil {
 		expiryTime := time.Until(*url.ExpiresAt)
@@ -147,47 +578,301 @@ func (s *URLServiceImpl) GetOriginalURL(ctx context.Context, shortCode string) (
 		}
 	}
 
-	if err := s.cache.SetWithTTL(ctx, cacheKey, url.OriginalURL, cacheTTL); err != nil {
+	cached := cachedURL{URL: *url, DestinationHash: url.DestinationHash}
+	if err := s.cache.SetWithTTL(ctx, cacheKey(url.DomainID, url.ShortCode), cached, cacheTTL); err != nil {
 		// Log error but continue; this is not critical
 		fmt.Printf("Error caching URL: %v\n", err)
 	}
+}
+
+// evictURL removes the URL entity from cache so redirects see its changes right away
+func (s *URLServiceImpl) evictURL(ctx context.Context, url *model.URL) {
+	if err := s.cache.Delete(ctx, cacheKey(url.DomainID, url.ShortCode)); err != nil {
+		fmt.Printf("Error invalidating URL cache: %v\n", err)
+	}
+}
+
+// HandleLinkEvents evicts the URL entities of relayed link changes from cache.
+// Changes are evicted right away by the instance making them, this is a
+// backstop for the entries a redirect cached again from a stale read
+func (s *URLServiceImpl) HandleLinkEvents(ctx context.Context, events []model.OutboxEvent) error {
+	for _, event := range events {
+		if event.AggregateType != model.AggregateLink || event.EventType == model.EventLinkCreated {
+			continue
+		}
+
+		var url model.URL
+		if err := json.Unmarshal(event.Payload, &url); err != nil {
+			log.Printf("error decoding %s event %d: %v", event.EventType, event.ID, err)
+			continue
+		}
+
+		if err := s.cache.Delete(ctx, cacheKey(url.DomainID, url.ShortCode)); err != nil {
+			return fmt.Errorf("error invalidating URL cache: %w", err)
+		}
+	}
 
-	return url.OriginalURL, nil
+	return nil
+}
+
+// linkAuditEvent returns the audit event of an action on a URL
+func linkAuditEvent(action model.AuditAction, url *model.URL) model.AuditEvent {
+	return model.AuditEvent{
+		WorkspaceID: url.WorkspaceID,
+		Action:      action,
+		TargetType:  model.AuditTargetLink,
+		TargetID:    strconv.FormatUint(uint64(url.ID), 10),
+	}
+}
+
+// cacheKey returns the cache key of a short code, scoped to its domain
+func cacheKey(domainID uint, shortCode string) string {
+	return fmt.Sprintf("%s%d:%s", CacheKeyPrefix, domainID, shortCode)
+}
+
+// shortURL builds the full short URL of a link on its domain
+func (s *URLServiceImpl) shortURL(ctx context.Context, url *model.URL) (string, error) {
+	domain, err := s.domainService.GetDomainByID(ctx, url.DomainID)
+	if err != nil {
+		return "", err
+	}
+
+	return shortURLFor(domain, url.ShortCode), nil
+}
+
+// shortURLFor builds the full short URL of a short code on a domain
+func shortURLFor(domain *model.Domain, shortCode string) string {
+	return fmt.Sprintf("%s/%s", domain.BaseURL(), shortCode)
 }
 
 // RecordVisit records a visit to a shortened URL
-func (s *URLServiceImpl) RecordVisit(ctx context.Context, urlID uint, ip, userAgent, referer string) error {
-	visit := &model.URLVisit{
-		URLID:     urlID,
-		IP:        ip,
-		UserAgent: userAgent,
-		Referer:   referer,
+func (s *URLServiceImpl) RecordVisit(ctx context.Context, url *model.URL, visit model.VisitInfo) error {
+	record := &model.URLVisit{
+		URLID:       url.ID,
+		IP:          visit.IP,
+		UserAgent:   visit.UserAgent,
+		Referer:     visit.Referer,
+		UTMCampaign: url.UTM.Campaign,
+		Source:      visit.Source,
+	}
+	if record.Source == "" {
+		record.Source = model.VisitSourceLink
+		visit.Source = model.VisitSourceLink
 	}
 
-	return s.urlRepo.CreateVisit(ctx, visit)
+	// redirects are metered but never blocked, links must keep working over quota
+	if url.WorkspaceID != 0 {
+		if _, err := s.usageService.Record(ctx, url.WorkspaceID, model.UsageRedirects); err != nil {
+			fmt.Printf("Error recording redirect usage: %v\n", err)
+		}
+	}
+
+	if err := s.urlRepo.CreateVisit(ctx, record); err != nil {
+		return err
+	}
+
+	s.clicks.Publish(ctx, url, visit, record.CreatedAt)
+
+	// visits are recorded off the redirect path, so queuing the event here doesn't slow redirects
+	s.webhooks.Emit(ctx, url.WorkspaceID, model.WebhookLinkClicked, model.WebhookClickData{
+		LinkID:    url.ID,
+		ShortCode: url.ShortCode,
+		Source:    record.Source,
+		Referer:   record.Referer,
+		UserAgent: record.UserAgent,
+		ClickedAt: record.CreatedAt,
+	})
+
+	return nil
 }
 
-// GetURLStats gets statistics for a shortened URL
-func (s *URLServiceImpl) GetURLStats(ctx context.Context, shortCode string) (*model.GetURLStatsResponse, error) {
-	url, err := s.urlRepo.FindByShortCode(ctx, shortCode)
+// GetURLStats gets statistics for a shortened URL on a domain
+func (s *URLServiceImpl) GetURLStats(ctx context.Context, domain *model.Domain, shortCode string) (*model.GetURLStatsResponse, error) {
+	url, err := s.FindURL(ctx, domain, shortCode)
 	if err != nil {
 		return nil, err
 	}
 
-	shortURL := fmt.Sprintf("%s/%s", s.domainName, shortCode)
-
 	stats := &model.GetURLStatsResponse{
-		ShortURL:    shortURL,
+		ShortURL:    shortURLFor(domain, shortCode),
 		OriginalURL: url.OriginalURL,
 		VisitCount:  url.VisitCount,
 		CreatedAt:   url.CreatedAt,
 		ExpiresAt:   url.ExpiresAt,
 	}
 
+	if !url.UTM.IsEmpty() {
+		stats.UTM = &url.UTM
+	}
+
+	// links are only health-checked periodically
+	if health, err := s.healthRepo.FindByURLID(ctx, url.ID); err == nil {
+		stats.Health = health
+	}
+
 	return stats, nil
 }
 
+// RefreshMetadata fetches the metadata of the destination of a URL again
+func (s *URLServiceImpl) RefreshMetadata(ctx context.Context, url *model.URL) (*model.LinkMetadata, error) {
+	return s.metadata.Refresh(ctx, url)
+}
+
+// GetURLPreview describes the destination of a URL for the interstitial preview page
+func (s *URLServiceImpl) GetURLPreview(ctx context.Context, url *model.URL) (*model.URLPreviewResponse, error) {
+	shortURL, err := s.shortURL(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	destination := url.Destination()
+	parsed, err := neturl.Parse(destination)
+	if err != nil {
+		return nil, fmt.Errorf("invalid destination url: %w", err)
+	}
+
+	preview := &model.URLPreviewResponse{
+		ShortURL:    shortURL,
+		Destination: destination,
+		Domain:      parsed.Hostname(),
+		CreatedAt:   url.CreatedAt,
+	}
+
+	return preview, nil
+}
+
+// GetQRCode renders a QR code pointing to the QR variant of the short URL,
+// caching rendered images by their parameters
+func (s *URLServiceImpl) GetQRCode(ctx context.Context, url *model.URL, format string, opts qrcode.Options) ([]byte, error) {
+	shortURL, err := s.shortURL(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	content := fmt.Sprintf("%s?%s=%s", shortURL, QRSourceParam, model.VisitSourceQR)
+
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%+v", content, format, opts)))
+	key := QRCacheKeyPrefix + hex.EncodeToString(hash[:])
+
+	// Try to get from cache first
+	if cached, err := s.cache.Get(ctx, key); err == nil {
+		return []byte(cached), nil
+	}
+
+	image, err := qrcode.Render(content, format, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.cache.SetWithTTL(ctx, key, image, DefaultCacheTTL); err != nil {
+		// Log error but continue; this is not critical
+		fmt.Printf("Error caching QR code: %v\n", err)
+	}
+
+	return image, nil
+}
+
+// GetCampaignStats gets the number of clicks per UTM campaign in the active workspace
+func (s *URLServiceImpl) GetCampaignStats(ctx context.Context) ([]model.CampaignStats, error) {
+	return s.urlRepo.CountVisitsByCampaign(ctx, requestctx.WorkspaceID(ctx))
+}
+
 // CleanupExpiredURLs removes expired URLs from the database
 func (s *URLServiceImpl) CleanupExpiredURLs(ctx context.Context) (int64, error) {
-	return s.urlRepo.DeleteExpired(ctx)
+	urls, err := s.urlRepo.DeleteExpired(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(len(urls)), nil
+}
+
+// RescanURLs looks up the reputation of the destination of every active link,
+// disabling the ones found malicious, and returns the number of disabled links
+func (s *URLServiceImpl) RescanURLs(ctx context.Context) (int, error) {
+	disabled := 0
+	err := s.urlRepo.EachActive(ctx, RescanBatchSize, func(urls []model.URL) error {
+		destinations := make([]string, len(urls))
+		for i := range urls {
+			destinations[i] = urls[i].OriginalURL
+		}
+
+		verdicts, err := s.safetyService.Scan(ctx, destinations)
+		if err != nil {
+			return err
+		}
+
+		for i := range urls {
+			url := &urls[i]
+			verdict := verdicts[url.OriginalURL]
+			if !verdict.Unsafe() || url.Whitelisted {
+				continue
+			}
+
+			if err := s.takeDown(ctx, url, model.DisabledByReputation, "known for "+verdict.Threat); err != nil {
+				log.Printf("error disabling malicious URL %d: %v", url.ID, err)
+				continue
+			}
+			log.Printf("disabled URL %d pointing to %q known for %s", url.ID, url.OriginalURL, verdict.Threat)
+			disabled++
+		}
+
+		return nil
+	})
+
+	return disabled, err
+}
+
+// ModerateURL applies a moderation decision to a URL: disabling it with the
+// reason shown to visitors, deleting it, or whitelisting it, which also lifts
+// any takedown and keeps it out of reputation rescans
+func (s *URLServiceImpl) ModerateURL(ctx context.Context, url *model.URL, action model.AbuseAction, reason string) error {
+	switch action {
+	case model.AbuseActionDisable:
+		return s.takeDown(ctx, url, model.DisabledByModeration, reason)
+	case model.AbuseActionDelete:
+		return s.deleteURL(ctx, url)
+	case model.AbuseActionWhitelist:
+		// whitelisting exempts the link from reputation rescans and lifts its
+		// takedown, which no member of its workspace may do
+		if !requestctx.IsOperator(ctx) {
+			return fmt.Errorf("%w: whitelisting requires a service operator", ErrForbidden)
+		}
+		before := *url
+		url.Whitelisted = true
+		if url.TakenDown() {
+			url.Disabled = false
+			url.DisabledBy = ""
+			url.DisabledReason = ""
+		}
+
+		if err := s.urlRepo.Update(ctx, url); err != nil {
+			return fmt.Errorf("failed to update URL: %w", err)
+		}
+
+		s.evictURL(ctx, url)
+		s.auditService.Record(ctx, linkAuditEvent(model.AuditLinkWhitelisted, url), &before, url)
+		return nil
+	case model.AbuseActionDismiss:
+		return nil
+	default:
+		return fmt.Errorf("unknown moderation action %q", action)
+	}
+}
+
+// takeDown disables a URL on behalf of someone other than its owner, evicting
+// it from the cache so the takedown applies immediately
+func (s *URLServiceImpl) takeDown(ctx context.Context, url *model.URL, disabledBy, reason string) error {
+	before := *url
+	url.Disabled = true
+	url.DisabledBy = disabledBy
+	url.DisabledReason = reason
+
+	if err := s.urlRepo.Update(ctx, url); err != nil {
+		return fmt.Errorf("failed to update URL: %w", err)
+	}
+
+	s.evictURL(ctx, url)
+	s.auditService.Record(ctx, linkAuditEvent(model.AuditLinkDisabled, url), &before, url)
+
+	return nil
 }
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"url_shortener/internal/model"
+	"url_shortener/internal/repository"
+)
+
+func TestCachedURLKeepsDestinationHash(t *testing.T) {
+	s := &URLServiceImpl{cache: newTestCache(t)}
+	ctx := context.Background()
+	domain := &model.Domain{ID: 1}
+
+	s.cacheURL(ctx, &model.URL{ID: 3, DomainID: domain.ID, ShortCode: "abc123", OriginalURL: "https://example.com/", DestinationHash: "0f1e2d"})
+
+	url, err := s.ResolveURL(ctx, domain, "abc123")
+	if err != nil {
+		t.Fatalf("ResolveURL: %v", err)
+	}
+	if url.ID != 3 || url.DestinationHash != "0f1e2d" {
+		t.Errorf("ResolveURL = %+v, want URL 3 with its destination hash", url)
+	}
+}
+
+// fakeWriteURLRepository accepts every write to a URL
+type fakeWriteURLRepository struct {
+	repository.URLRepository
+}
+
+func (r *fakeWriteURLRepository) Update(ctx context.Context, url *model.URL) error {
+	return nil
+}
+
+func (r *fakeWriteURLRepository) Delete(ctx context.Context, url *model.URL) error {
+	return nil
+}
+
+func TestTakeDownEvictsCachedURL(t *testing.T) {
+	s := &URLServiceImpl{urlRepo: &fakeWriteURLRepository{}, auditService: &fakeAuditService{}, cache: newTestCache(t)}
+	ctx := context.Background()
+	url := &model.URL{ID: 3, DomainID: 1, ShortCode: "abc123", OriginalURL: "https://example.com/"}
+	s.cacheURL(ctx, url)
+
+	if err := s.takeDown(ctx, url, model.DisabledByModeration, "phishing"); err != nil {
+		t.Fatalf("takeDown: %v", err)
+	}
+
+	if _, err := s.cache.Get(ctx, cacheKey(url.DomainID, url.ShortCode)); err == nil {
+		t.Error("the taken down URL is still cached")
+	}
+}
+
+func TestDeleteURLEvictsCachedURL(t *testing.T) {
+	s := &URLServiceImpl{urlRepo: &fakeWriteURLRepository{}, auditService: &fakeAuditService{}, cache: newTestCache(t)}
+	ctx := context.Background()
+	url := &model.URL{ID: 3, DomainID: 1, ShortCode: "abc123", OriginalURL: "https://example.com/"}
+	s.cacheURL(ctx, url)
+
+	if err := s.deleteURL(ctx, url); err != nil {
+		t.Fatalf("deleteURL: %v", err)
+	}
+
+	if _, err := s.cache.Get(ctx, cacheKey(url.DomainID, url.ShortCode)); err == nil {
+		t.Error("the deleted URL is still cached")
+	}
+}
@@ -0,0 +1,293 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"url_shortener/internal/model"
+	"url_shortener/internal/repository"
+	"url_shortener/internal/requestctx"
+	"url_shortener/pkg/cache"
+)
+
+const (
+	// UsageKeyPrefix prefixes the Redis usage counters, keyed by period, workspace and metric
+	UsageKeyPrefix = "usage:"
+	// UsageCounterTTL keeps the Redis counters of a month around long enough to be reconciled
+	UsageCounterTTL = 62 * 24 * time.Hour
+	// UsagePlanKeyPrefix prefixes the cached plans of workspaces, keyed by workspace
+	UsagePlanKeyPrefix = "workspace_plan:"
+	// UsagePlanCacheTTL is how long the plan of a workspace is cached for metering
+	UsagePlanCacheTTL = 5 * time.Minute
+)
+
+// interface for usage metering operations
+type UsageService interface {
+	Record(ctx context.Context, workspaceID uint, metric model.UsageMetric) (int64, error)
+	Meter(ctx context.Context, workspaceID uint, metric model.UsageMetric) error
+	Reserve(ctx context.Context, workspaceID uint, metric model.UsageMetric) error
+	Release(ctx context.Context, workspaceID uint, metric model.UsageMetric)
+	GetUsage(ctx context.Context) (*model.UsageResponse, error)
+	Reconcile(ctx context.Context) (int, error)
+	ChangePlan(ctx context.Context, workspaceID uint, plan string) (*model.Workspace, error)
+}
+
+// implements UsageService interface
+type UsageServiceImpl struct {
+	usageRepo     repository.UsageRepository
+	workspaceRepo repository.WorkspaceRepository
+	cache         *cache.RedisClient
+	plans         map[string]model.PlanLimits
+}
+
+// create a new usage service enforcing the limits of the given plans
+func NewUsageService(usageRepo repository.UsageRepository, workspaceRepo repository.WorkspaceRepository, cache *cache.RedisClient, plans map[string]model.PlanLimits) UsageService {
+	return &UsageServiceImpl{
+		usageRepo:     usageRepo,
+		workspaceRepo: workspaceRepo,
+		cache:         cache,
+		plans:         plans,
+	}
+}
+
+// Record increments the usage of a workspace for the current month and returns
+// the new value. A missing Redis counter is seeded from Postgres before its
+// first increment, so every value returned counts all the usage of the month
+func (s *UsageServiceImpl) Record(ctx context.Context, workspaceID uint, metric model.UsageMetric) (int64, error) {
+	period := model.UsagePeriod(time.Now())
+	key := usageKey(period, workspaceID, metric)
+
+	value, exists, err := s.cache.IncrementExisting(ctx, key)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record usage: %w", err)
+	}
+	if exists {
+		return value, nil
+	}
+
+	counter, err := s.usageRepo.Find(ctx, workspaceID, period)
+	if err != nil {
+		return 0, err
+	}
+	// concurrent requests race to seed the counter, only the first one does
+	if _, err := s.cache.SetNX(ctx, key, counter.Usage.Get(metric), UsageCounterTTL); err != nil {
+		return 0, fmt.Errorf("failed to record usage: %w", err)
+	}
+
+	value, err = s.cache.Increment(ctx, key)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record usage: %w", err)
+	}
+
+	return value, nil
+}
+
+// Meter records one use of a metric and fails with ErrQuotaExceeded once the
+// workspace goes over the limit of its plan
+func (s *UsageServiceImpl) Meter(ctx context.Context, workspaceID uint, metric model.UsageMetric) error {
+	value, err := s.Record(ctx, workspaceID, metric)
+	if err != nil {
+		// metering must not take the API down with Redis
+		fmt.Printf("Error metering usage: %v\n", err)
+		return nil
+	}
+
+	limits, err := s.limits(ctx, workspaceID)
+	if err != nil {
+		return err
+	}
+	if limits.Exceeded(metric, value) {
+		return quotaError(metric, limits)
+	}
+
+	return nil
+}
+
+// Reserve records one use of a metric ahead of the operation making it, and
+// fails with ErrQuotaExceeded, without recording it, when the workspace already
+// reached the limit of its plan. The use is recorded and compared against the
+// limit in one increment, so concurrent operations cannot overshoot the limit
+func (s *UsageServiceImpl) Reserve(ctx context.Context, workspaceID uint, metric model.UsageMetric) error {
+	limits, err := s.limits(ctx, workspaceID)
+	if err != nil {
+		return err
+	}
+
+	value, err := s.Record(ctx, workspaceID, metric)
+	if err != nil {
+		return err
+	}
+	if limits.Exceeded(metric, value) {
+		s.Release(ctx, workspaceID, metric)
+		return quotaError(metric, limits)
+	}
+
+	return nil
+}
+
+// Release gives back a use reserved for an operation that did not happen
+func (s *UsageServiceImpl) Release(ctx context.Context, workspaceID uint, metric model.UsageMetric) {
+	key := usageKey(model.UsagePeriod(time.Now()), workspaceID, metric)
+	if _, err := s.cache.IncrementBy(ctx, key, -1); err != nil {
+		log.Printf("error releasing %s usage of workspace %d: %v", metric, workspaceID, err)
+	}
+}
+
+// GetUsage gets the usage of the active workspace for the current month
+func (s *UsageServiceImpl) GetUsage(ctx context.Context) (*model.UsageResponse, error) {
+	workspace, err := s.workspaceRepo.FindByID(ctx, requestctx.WorkspaceID(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	response := &model.UsageResponse{
+		WorkspaceID: workspace.ID,
+		Plan:        workspace.Plan,
+		Period:      model.UsagePeriod(time.Now()),
+		Limits:      s.plans[workspace.Plan],
+	}
+
+	for _, metric := range model.UsageMetrics {
+		value, err := s.current(ctx, workspace.ID, response.Period, metric)
+		if err != nil {
+			return nil, err
+		}
+		response.Usage.Set(metric, value)
+	}
+
+	return response, nil
+}
+
+// Reconcile copies the Redis usage counters to Postgres and returns the number
+// of workspace counters written
+func (s *UsageServiceImpl) Reconcile(ctx context.Context) (int, error) {
+	keys, err := s.cache.ScanKeys(ctx, UsageKeyPrefix+"*")
+	if err != nil {
+		return 0, err
+	}
+
+	counters := make(map[string]*model.UsageCounter)
+	for _, key := range keys {
+		period, workspaceID, metric, ok := parseUsageKey(key)
+		if !ok {
+			continue
+		}
+
+		raw, err := s.cache.Get(ctx, key)
+		if err != nil {
+			continue
+		}
+		value, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		id := fmt.Sprintf("%s:%d", period, workspaceID)
+		counter, exists := counters[id]
+		if !exists {
+			counter = &model.UsageCounter{WorkspaceID: workspaceID, Period: period}
+			counters[id] = counter
+		}
+		counter.Usage.Set(metric, value)
+	}
+
+	written := 0
+	for _, counter := range counters {
+		if err := s.usageRepo.Upsert(ctx, counter); err != nil {
+			log.Printf("error reconciling usage of workspace %d: %v", counter.WorkspaceID, err)
+			continue
+		}
+		written++
+	}
+
+	return written, nil
+}
+
+// current returns the usage of a workspace, the Redis counter being ahead of
+// Postgres unless Redis lost it
+func (s *UsageServiceImpl) current(ctx context.Context, workspaceID uint, period string, metric model.UsageMetric) (int64, error) {
+	counter, err := s.usageRepo.Find(ctx, workspaceID, period)
+	if err != nil {
+		return 0, err
+	}
+	value := counter.Usage.Get(metric)
+
+	if raw, err := s.cache.Get(ctx, usageKey(period, workspaceID, metric)); err == nil {
+		if cached, err := strconv.ParseInt(raw, 10, 64); err == nil && cached > value {
+			value = cached
+		}
+	}
+
+	return value, nil
+}
+
+// limits returns the plan limits of a workspace, its plan being cached so
+// that metering API calls does not load the workspace every time
+func (s *UsageServiceImpl) limits(ctx context.Context, workspaceID uint) (model.PlanLimits, error) {
+	key := fmt.Sprintf("%s%d", UsagePlanKeyPrefix, workspaceID)
+	plan, err := s.cache.Get(ctx, key)
+	if err != nil {
+		workspace, err := s.workspaceRepo.FindByID(ctx, workspaceID)
+		if err != nil {
+			return model.PlanLimits{}, err
+		}
+
+		plan = workspace.Plan
+		if err := s.cache.SetWithTTL(ctx, key, plan, UsagePlanCacheTTL); err != nil {
+			// Log error but continue; this is not critical
+			fmt.Printf("Error caching workspace plan: %v\n", err)
+		}
+	}
+
+	return s.plans[plan], nil
+}
+
+// ChangePlan moves a workspace to another plan, dropping the cached plan so
+// that its new limits apply to the next operation. Restricted to service operators
+func (s *UsageServiceImpl) ChangePlan(ctx context.Context, workspaceID uint, plan string) (*model.Workspace, error) {
+	if !requestctx.IsOperator(ctx) {
+		return nil, fmt.Errorf("%w: changing plans requires a service operator", ErrForbidden)
+	}
+	if _, ok := s.plans[plan]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownPlan, plan)
+	}
+
+	if err := s.workspaceRepo.UpdatePlan(ctx, workspaceID, plan); err != nil {
+		return nil, err
+	}
+
+	if err := s.cache.Delete(ctx, fmt.Sprintf("%s%d", UsagePlanKeyPrefix, workspaceID)); err != nil {
+		return nil, fmt.Errorf("failed to drop cached plan: %w", err)
+	}
+
+	return s.workspaceRepo.FindByID(ctx, workspaceID)
+}
+
+// quotaError describes the limit a workspace went over
+func quotaError(metric model.UsageMetric, limits model.PlanLimits) error {
+	return fmt.Errorf("%w: monthly %s limit of %d reached", ErrQuotaExceeded, metric, limits.Limit(metric))
+}
+
+// usageKey returns the Redis key of a usage counter
+func usageKey(period string, workspaceID uint, metric model.UsageMetric) string {
+	return fmt.Sprintf("%s%s:%d:%s", UsageKeyPrefix, period, workspaceID, metric)
+}
+
+// parseUsageKey parses a key built by usageKey
+func parseUsageKey(key string) (string, uint, model.UsageMetric, bool) {
+	parts := strings.Split(strings.TrimPrefix(key, UsageKeyPrefix), ":")
+	if len(parts) != 3 {
+		return "", 0, "", false
+	}
+
+	workspaceID, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return "", 0, "", false
+	}
+
+	return parts[0], uint(workspaceID), model.UsageMetric(parts[2]), true
+}
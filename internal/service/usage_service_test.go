@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"url_shortener/internal/model"
+	"url_shortener/internal/repository"
+	"url_shortener/internal/requestctx"
+)
+
+// fakeWorkspaceRepository serves a single workspace, counting its loads
+type fakeWorkspaceRepository struct {
+	repository.WorkspaceRepository
+	workspace model.Workspace
+	loads     atomic.Int64
+}
+
+func (r *fakeWorkspaceRepository) FindByID(ctx context.Context, id uint) (*model.Workspace, error) {
+	r.loads.Add(1)
+	workspace := r.workspace
+	return &workspace, nil
+}
+
+func (r *fakeWorkspaceRepository) UpdatePlan(ctx context.Context, id uint, plan string) error {
+	r.workspace.Plan = plan
+	return nil
+}
+
+// fakeUsageRepository serves the usage reconciled to Postgres
+type fakeUsageRepository struct {
+	usage model.Usage
+}
+
+func (r *fakeUsageRepository) Find(ctx context.Context, workspaceID uint, period string) (*model.UsageCounter, error) {
+	return &model.UsageCounter{WorkspaceID: workspaceID, Period: period, Usage: r.usage}, nil
+}
+
+func (r *fakeUsageRepository) Upsert(ctx context.Context, counter *model.UsageCounter) error {
+	return nil
+}
+
+// newUsageFixture returns a usage service metering a workspace on a plan of
+// apiCalls API calls a month, reconciled usage holding reconciled calls
+func newUsageFixture(t *testing.T, apiCalls, reconciled int64) (UsageService, *fakeWorkspaceRepository) {
+	t.Helper()
+
+	workspaces := &fakeWorkspaceRepository{workspace: model.Workspace{ID: testWorkspaceID, Plan: "starter"}}
+	usage := &fakeUsageRepository{usage: model.Usage{APICalls: reconciled}}
+	plans := map[string]model.PlanLimits{"starter": {APICalls: apiCalls}}
+
+	return NewUsageService(usage, workspaces, newTestCache(t), plans), workspaces
+}
+
+// meterConcurrently meters n API calls at once and returns the number allowed
+func meterConcurrently(t *testing.T, s UsageService, n int) int64 {
+	t.Helper()
+
+	var (
+		wg      sync.WaitGroup
+		allowed atomic.Int64
+	)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := s.Meter(context.Background(), testWorkspaceID, model.UsageAPICalls)
+			switch {
+			case err == nil:
+				allowed.Add(1)
+			case !errors.Is(err, ErrQuotaExceeded):
+				t.Errorf("Meter: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return allowed.Load()
+}
+
+func TestMeterEnforcesLimitUnderConcurrency(t *testing.T) {
+	s, _ := newUsageFixture(t, 10, 0)
+
+	if allowed := meterConcurrently(t, s, 50); allowed != 10 {
+		t.Errorf("Meter allowed %d of 50 concurrent calls, want 10", allowed)
+	}
+}
+
+func TestMeterCountsReconciledUsage(t *testing.T) {
+	s, _ := newUsageFixture(t, 10, 8)
+
+	if allowed := meterConcurrently(t, s, 20); allowed != 2 {
+		t.Errorf("Meter allowed %d calls with 8 of 10 used, want 2", allowed)
+	}
+}
+
+func TestMeterCachesPlan(t *testing.T) {
+	s, workspaces := newUsageFixture(t, 0, 0)
+
+	for i := 0; i < 5; i++ {
+		if err := s.Meter(context.Background(), testWorkspaceID, model.UsageAPICalls); err != nil {
+			t.Fatalf("Meter: %v", err)
+		}
+	}
+
+	if loads := workspaces.loads.Load(); loads != 1 {
+		t.Errorf("workspace loaded %d times for 5 calls, want once", loads)
+	}
+}
+
+func TestReserveEnforcesLimitUnderConcurrency(t *testing.T) {
+	workspaces := &fakeWorkspaceRepository{workspace: model.Workspace{ID: testWorkspaceID, Plan: "starter"}}
+	plans := map[string]model.PlanLimits{"starter": {Links: 5}}
+	s := NewUsageService(&fakeUsageRepository{}, workspaces, newTestCache(t), plans)
+	ctx := context.Background()
+
+	var (
+		wg      sync.WaitGroup
+		allowed atomic.Int64
+	)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := s.Reserve(ctx, testWorkspaceID, model.UsageLinks)
+			switch {
+			case err == nil:
+				allowed.Add(1)
+			case !errors.Is(err, ErrQuotaExceeded):
+				t.Errorf("Reserve: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := allowed.Load(); got != 5 {
+		t.Errorf("Reserve allowed %d of 20 concurrent links, want 5", got)
+	}
+
+	// a link that failed to be created frees its unit for the next one
+	s.Release(ctx, testWorkspaceID, model.UsageLinks)
+	if err := s.Reserve(ctx, testWorkspaceID, model.UsageLinks); err != nil {
+		t.Errorf("Reserve after Release: %v", err)
+	}
+}
+
+func TestChangePlanAppliesNewLimitsRightAway(t *testing.T) {
+	workspaces := &fakeWorkspaceRepository{workspace: model.Workspace{ID: testWorkspaceID, Plan: "starter"}}
+	plans := map[string]model.PlanLimits{"starter": {APICalls: 1}, "growth": {APICalls: 10}}
+	s := NewUsageService(&fakeUsageRepository{}, workspaces, newTestCache(t), plans)
+	ctx := context.Background()
+
+	if err := s.Meter(ctx, testWorkspaceID, model.UsageAPICalls); err != nil {
+		t.Fatalf("Meter: %v", err)
+	}
+	if err := s.Meter(ctx, testWorkspaceID, model.UsageAPICalls); !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("Meter over the starter limit = %v, want ErrQuotaExceeded", err)
+	}
+
+	if _, err := s.ChangePlan(ctx, testWorkspaceID, "growth"); !errors.Is(err, ErrForbidden) {
+		t.Errorf("ChangePlan by a non operator = %v, want ErrForbidden", err)
+	}
+	if _, err := s.ChangePlan(requestctx.WithOperator(ctx), testWorkspaceID, "enterprise"); !errors.Is(err, ErrUnknownPlan) {
+		t.Errorf("ChangePlan to an unknown plan = %v, want ErrUnknownPlan", err)
+	}
+	if _, err := s.ChangePlan(requestctx.WithOperator(ctx), testWorkspaceID, "growth"); err != nil {
+		t.Fatalf("ChangePlan: %v", err)
+	}
+
+	// the cached starter plan must not outlive the change
+	if err := s.Meter(ctx, testWorkspaceID, model.UsageAPICalls); err != nil {
+		t.Errorf("Meter after moving to growth: %v", err)
+	}
+}
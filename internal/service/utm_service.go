@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"url_shortener/internal/model"
+	"url_shortener/internal/repository"
+	"url_shortener/internal/requestctx"
+)
+
+// interface for UTM template operations
+type UTMService interface {
+	CreateTemplate(ctx context.Context, req model.CreateUTMTemplateRequest) (*model.UTMTemplate, error)
+	GetTemplate(ctx context.Context, id uint) (*model.UTMTemplate, error)
+	ListTemplates(ctx context.Context) ([]model.UTMTemplate, error)
+	DeleteTemplate(ctx context.Context, id uint) error
+}
+
+// implements UTMService interface
+type UTMServiceImpl struct {
+	utmRepo      repository.UTMTemplateRepository
+	auditService AuditService
+}
+
+// create a new UTM service
+func NewUTMService(utmRepo repository.UTMTemplateRepository, auditService AuditService) UTMService {
+	return &UTMServiceImpl{
+		utmRepo:      utmRepo,
+		auditService: auditService,
+	}
+}
+
+// CreateTemplate creates a reusable UTM template in the active workspace,
+// private to the calling user when personal
+func (s *UTMServiceImpl) CreateTemplate(ctx context.Context, req model.CreateUTMTemplateRequest) (*model.UTMTemplate, error) {
+	if req.UTM.IsEmpty() {
+		return nil, fmt.Errorf("template must define at least one UTM parameter")
+	}
+
+	template := &model.UTMTemplate{
+		WorkspaceID: requestctx.WorkspaceID(ctx),
+		Name:        req.Name,
+		UTM:         req.UTM,
+	}
+	if req.Personal {
+		template.OwnerID = requestctx.UserID(ctx)
+	}
+
+	if err := s.utmRepo.Create(ctx, template); err != nil {
+		return nil, fmt.Errorf("failed to create UTM template: %w", err)
+	}
+
+	s.auditService.Record(ctx, templateAuditEvent(model.AuditUTMTemplateCreated, template), nil, template)
+
+	return template, nil
+}
+
+// GetTemplate gets a UTM template by id
+func (s *UTMServiceImpl) GetTemplate(ctx context.Context, id uint) (*model.UTMTemplate, error) {
+	return findAccessibleTemplate(ctx, s.utmRepo, id)
+}
+
+// ListTemplates lists the UTM templates available to the calling user
+func (s *UTMServiceImpl) ListTemplates(ctx context.Context) ([]model.UTMTemplate, error) {
+	return s.utmRepo.FindAllForUser(ctx, requestctx.WorkspaceID(ctx), requestctx.UserID(ctx))
+}
+
+// DeleteTemplate deletes a UTM template
+func (s *UTMServiceImpl) DeleteTemplate(ctx context.Context, id uint) error {
+	template, err := findAccessibleTemplate(ctx, s.utmRepo, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.utmRepo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete UTM template: %w", err)
+	}
+
+	s.auditService.Record(ctx, templateAuditEvent(model.AuditUTMTemplateDeleted, template), template, nil)
+
+	return nil
+}
+
+// templateAuditEvent returns the audit event of an action on a UTM template
+func templateAuditEvent(action model.AuditAction, template *model.UTMTemplate) model.AuditEvent {
+	return model.AuditEvent{
+		WorkspaceID: template.WorkspaceID,
+		Action:      action,
+		TargetType:  model.AuditTargetUTMTemplate,
+		TargetID:    strconv.FormatUint(uint64(template.ID), 10),
+	}
+}
+
+// findAccessibleTemplate finds a UTM template the calling user may use
+func findAccessibleTemplate(ctx context.Context, utmRepo repository.UTMTemplateRepository, id uint) (*model.UTMTemplate, error) {
+	template, err := utmRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !template.AccessibleBy(requestctx.WorkspaceID(ctx), requestctx.UserID(ctx)) {
+		return nil, fmt.Errorf("UTM template %d not found", id)
+	}
+
+	return template, nil
+}
@@ -0,0 +1,374 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"url_shortener/internal/model"
+	"url_shortener/internal/repository"
+	"url_shortener/internal/requestctx"
+	"url_shortener/pkg/safety"
+	"url_shortener/pkg/webhook"
+)
+
+const (
+	// WebhookBatchSize is the number of due deliveries claimed at once
+	WebhookBatchSize = 50
+	// WebhookConcurrency is the number of deliveries sent in parallel
+	WebhookConcurrency = 10
+	// WebhookRetryBase is the delay before the first retry, doubled on every failure
+	WebhookRetryBase = 30 * time.Second
+	// WebhookRetryMax caps the delay between retries
+	WebhookRetryMax = 6 * time.Hour
+	// DefaultDeliveryPageSize is the number of deliveries returned when no limit is given
+	DefaultDeliveryPageSize = 20
+)
+
+// interface for webhook operations
+type WebhookService interface {
+	CreateSubscription(ctx context.Context, req model.CreateWebhookRequest) (*model.CreateWebhookResponse, error)
+	ListSubscriptions(ctx context.Context) ([]model.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id uint) error
+	ListDeliveries(ctx context.Context, subscriptionID uint, req model.ListWebhookDeliveriesRequest) (*model.ListWebhookDeliveriesResponse, error)
+	ReplayDelivery(ctx context.Context, subscriptionID, deliveryID uint) (*model.WebhookDelivery, error)
+	Emit(ctx context.Context, workspaceID uint, event model.WebhookEvent, data interface{})
+	HandleLinkEvents(ctx context.Context, events []model.OutboxEvent) error
+	DeliverDue(ctx context.Context) (int, error)
+}
+
+// implements WebhookService interface
+type WebhookServiceImpl struct {
+	webhookRepo  repository.WebhookRepository
+	client       *http.Client
+	checker      *safety.Checker
+	auditService AuditService
+	maxAttempts  int
+}
+
+// create a new webhook service posting deliveries with client and giving up
+// on a delivery after maxAttempts. Endpoints must pass the checks of checker
+func NewWebhookService(webhookRepo repository.WebhookRepository, client *http.Client, checker *safety.Checker, auditService AuditService, maxAttempts int) WebhookService {
+	return &WebhookServiceImpl{
+		webhookRepo:  webhookRepo,
+		client:       client,
+		checker:      checker,
+		auditService: auditService,
+		maxAttempts:  maxAttempts,
+	}
+}
+
+// CreateSubscription subscribes an endpoint to events of the active workspace.
+// Endpoints on internal hosts are rejected with ErrUnsafeURL
+func (s *WebhookServiceImpl) CreateSubscription(ctx context.Context, req model.CreateWebhookRequest) (*model.CreateWebhookResponse, error) {
+	if err := s.checker.Check(req.URL); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsafeURL, err)
+	}
+
+	secret, err := webhook.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	subscription := &model.WebhookSubscription{
+		WorkspaceID: requestctx.WorkspaceID(ctx),
+		URL:         req.URL,
+		Secret:      secret,
+		Events:      model.JoinWebhookEvents(req.Events),
+		Active:      true,
+	}
+
+	if err := s.webhookRepo.CreateSubscription(ctx, subscription); err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	s.auditService.Record(ctx, webhookAuditEvent(model.AuditWebhookCreated, subscription), nil, subscription)
+
+	return &model.CreateWebhookResponse{
+		WebhookSubscription: *subscription,
+		Secret:              secret,
+	}, nil
+}
+
+// ListSubscriptions lists the webhook subscriptions of the active workspace
+func (s *WebhookServiceImpl) ListSubscriptions(ctx context.Context) ([]model.WebhookSubscription, error) {
+	return s.webhookRepo.FindSubscriptions(ctx, requestctx.WorkspaceID(ctx))
+}
+
+// DeleteSubscription deletes a webhook subscription of the active workspace,
+// its pending deliveries end up in the dead-letter list
+func (s *WebhookServiceImpl) DeleteSubscription(ctx context.Context, id uint) error {
+	subscription, err := s.findWorkspaceSubscription(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.webhookRepo.DeleteSubscription(ctx, subscription); err != nil {
+		return err
+	}
+
+	s.auditService.Record(ctx, webhookAuditEvent(model.AuditWebhookDeleted, subscription), subscription, nil)
+
+	return nil
+}
+
+// ListDeliveries lists the delivery log of a webhook subscription of the active workspace
+func (s *WebhookServiceImpl) ListDeliveries(ctx context.Context, subscriptionID uint, req model.ListWebhookDeliveriesRequest) (*model.ListWebhookDeliveriesResponse, error) {
+	if _, err := s.findWorkspaceSubscription(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+
+	if req.Limit <= 0 {
+		req.Limit = DefaultDeliveryPageSize
+	}
+
+	deliveries, total, err := s.webhookRepo.FindDeliveries(ctx, subscriptionID, req.Status, req.Limit, req.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ListWebhookDeliveriesResponse{
+		Deliveries: deliveries,
+		Total:      total,
+		Limit:      req.Limit,
+		Offset:     req.Offset,
+	}, nil
+}
+
+// ReplayDelivery queues a new delivery of the payload of a past delivery
+func (s *WebhookServiceImpl) ReplayDelivery(ctx context.Context, subscriptionID, deliveryID uint) (*model.WebhookDelivery, error) {
+	if _, err := s.findWorkspaceSubscription(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+
+	original, err := s.webhookRepo.FindDelivery(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if original.SubscriptionID != subscriptionID {
+		return nil, fmt.Errorf("webhook delivery %d not found", deliveryID)
+	}
+
+	replay := []model.WebhookDelivery{{
+		WorkspaceID:    original.WorkspaceID,
+		SubscriptionID: original.SubscriptionID,
+		Event:          original.Event,
+		Payload:        original.Payload,
+		Status:         model.DeliveryStatusPending,
+		NextAttemptAt:  time.Now(),
+		ReplayOf:       &original.ID,
+	}}
+
+	if err := s.webhookRepo.CreateDeliveries(ctx, replay); err != nil {
+		return nil, fmt.Errorf("failed to replay webhook delivery: %w", err)
+	}
+
+	return &replay[0], nil
+}
+
+// Emit queues an event for every subscription of a workspace wanting it. The
+// deliveries are sent by DeliverDue, so emitting only costs a database write
+func (s *WebhookServiceImpl) Emit(ctx context.Context, workspaceID uint, event model.WebhookEvent, data interface{}) {
+	if err := s.queue(ctx, workspaceID, event, data, time.Now(), nil); err != nil {
+		log.Printf("error emitting webhook event %s: %v", event, err)
+	}
+}
+
+// HandleLinkEvents queues the webhook events of relayed link changes, so a
+// change is announced once it is committed and no committed change goes
+// unannounced. Deliveries are queued once per outbox event, relaying the same
+// events again queues nothing new
+func (s *WebhookServiceImpl) HandleLinkEvents(ctx context.Context, events []model.OutboxEvent) error {
+	for i := range events {
+		outboxEvent := &events[i]
+		event, ok := linkWebhookEvents[outboxEvent.EventType]
+		if !ok || outboxEvent.AggregateType != model.AggregateLink {
+			continue
+		}
+
+		if err := s.queue(ctx, outboxEvent.WorkspaceID, event, json.RawMessage(outboxEvent.Payload), outboxEvent.CreatedAt, &outboxEvent.ID); err != nil {
+			return fmt.Errorf("error emitting webhook event %s: %w", event, err)
+		}
+	}
+
+	return nil
+}
+
+// linkWebhookEvents maps the outbox link events to the webhook events announcing them
+var linkWebhookEvents = map[string]model.WebhookEvent{
+	model.EventLinkCreated: model.WebhookLinkCreated,
+	model.EventLinkUpdated: model.WebhookLinkUpdated,
+	model.EventLinkDeleted: model.WebhookLinkDeleted,
+	model.EventLinkExpired: model.WebhookLinkExpired,
+}
+
+// queue creates the deliveries of an event for the subscriptions of a workspace
+// wanting it, at most once per subscription for events of the outbox
+func (s *WebhookServiceImpl) queue(ctx context.Context, workspaceID uint, event model.WebhookEvent, data interface{}, occurredAt time.Time, outboxEventID *uint) error {
+	if workspaceID == 0 {
+		return nil
+	}
+
+	subscriptions, err := s.webhookRepo.FindActiveSubscriptions(ctx, workspaceID)
+	if err != nil {
+		return err
+	}
+
+	var deliveries []model.WebhookDelivery
+	var payload []byte
+	for _, subscription := range subscriptions {
+		if !subscription.Accepts(event) {
+			continue
+		}
+
+		if payload == nil {
+			payload, err = json.Marshal(model.WebhookPayload{
+				Event:       event,
+				WorkspaceID: workspaceID,
+				OccurredAt:  occurredAt.UTC(),
+				Data:        data,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		deliveries = append(deliveries, model.WebhookDelivery{
+			WorkspaceID:    workspaceID,
+			SubscriptionID: subscription.ID,
+			Event:          event,
+			Payload:        payload,
+			Status:         model.DeliveryStatusPending,
+			NextAttemptAt:  time.Now(),
+			OutboxEventID:  outboxEventID,
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	return s.webhookRepo.CreateDeliveries(ctx, deliveries)
+}
+
+// DeliverDue sends a batch of due deliveries and returns the number that succeeded
+func (s *WebhookServiceImpl) DeliverDue(ctx context.Context) (int, error) {
+	// deliveries are leased for longer than a delivery can take
+	deliveries, err := s.webhookRepo.ClaimDueDeliveries(ctx, WebhookBatchSize, 2*s.client.Timeout+time.Minute)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		succeeded int
+	)
+	slots := make(chan struct{}, WebhookConcurrency)
+
+	for i := range deliveries {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(delivery *model.WebhookDelivery) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			if s.deliver(ctx, delivery) {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}
+		}(&deliveries[i])
+	}
+	wg.Wait()
+
+	return succeeded, nil
+}
+
+// deliver attempts a delivery once and schedules its retry on failure
+func (s *WebhookServiceImpl) deliver(ctx context.Context, delivery *model.WebhookDelivery) bool {
+	delivery.Attempts++
+	statusCode, err := s.send(ctx, delivery)
+	delivery.LastStatusCode = statusCode
+
+	now := time.Now()
+	switch {
+	case err == nil:
+		delivery.Status = model.DeliveryStatusSucceeded
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+	case delivery.Subscription.ID == 0 || delivery.Attempts >= s.maxAttempts:
+		delivery.Status = model.DeliveryStatusDead
+		delivery.LastError = err.Error()
+	default:
+		delivery.LastError = err.Error()
+		delivery.NextAttemptAt = now.Add(webhook.Backoff(delivery.Attempts, WebhookRetryBase, WebhookRetryMax))
+	}
+
+	if err := s.webhookRepo.UpdateDelivery(ctx, delivery); err != nil {
+		log.Printf("error updating webhook delivery %d: %v", delivery.ID, err)
+	}
+
+	return delivery.Status == model.DeliveryStatusSucceeded
+}
+
+// send posts the signed payload of a delivery to its subscription endpoint
+func (s *WebhookServiceImpl) send(ctx context.Context, delivery *model.WebhookDelivery) (int, error) {
+	subscription := delivery.Subscription
+	if subscription.ID == 0 {
+		return 0, fmt.Errorf("webhook subscription was deleted")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("invalid webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "url-shortener-webhooks/1.0")
+	req.Header.Set(webhook.EventHeader, string(delivery.Event))
+	req.Header.Set(webhook.DeliveryHeader, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(webhook.SignatureHeader, webhook.Sign(subscription.Secret, time.Now(), delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded with status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// webhookAuditEvent builds the audit event of an action on a webhook subscription
+func webhookAuditEvent(action model.AuditAction, subscription *model.WebhookSubscription) model.AuditEvent {
+	return model.AuditEvent{
+		WorkspaceID: subscription.WorkspaceID,
+		Action:      action,
+		TargetType:  model.AuditTargetWebhook,
+		TargetID:    strconv.FormatUint(uint64(subscription.ID), 10),
+	}
+}
+
+// findWorkspaceSubscription finds a webhook subscription of the active workspace
+func (s *WebhookServiceImpl) findWorkspaceSubscription(ctx context.Context, id uint) (*model.WebhookSubscription, error) {
+	subscription, err := s.webhookRepo.FindSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if subscription.WorkspaceID != requestctx.WorkspaceID(ctx) {
+		return nil, fmt.Errorf("webhook %d not found", id)
+	}
+
+	return subscription, nil
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"url_shortener/internal/model"
+	"url_shortener/internal/repository"
+)
+
+// fakeWebhookRepository serves a single subscription and keeps the deliveries
+// queued, skipping the ones of an outbox event already queued for the
+// subscription as the unique index does
+type fakeWebhookRepository struct {
+	repository.WebhookRepository
+	subscription model.WebhookSubscription
+	deliveries   []model.WebhookDelivery
+}
+
+func (r *fakeWebhookRepository) FindActiveSubscriptions(ctx context.Context, workspaceID uint) ([]model.WebhookSubscription, error) {
+	if workspaceID != r.subscription.WorkspaceID {
+		return nil, nil
+	}
+
+	return []model.WebhookSubscription{r.subscription}, nil
+}
+
+func (r *fakeWebhookRepository) CreateDeliveries(ctx context.Context, deliveries []model.WebhookDelivery) error {
+next:
+	for _, delivery := range deliveries {
+		for _, queued := range r.deliveries {
+			if delivery.OutboxEventID != nil && queued.OutboxEventID != nil &&
+				*delivery.OutboxEventID == *queued.OutboxEventID && delivery.SubscriptionID == queued.SubscriptionID {
+				continue next
+			}
+		}
+		r.deliveries = append(r.deliveries, delivery)
+	}
+
+	return nil
+}
+
+func TestHandleLinkEventsQueuesOncePerOutboxEvent(t *testing.T) {
+	repo := &fakeWebhookRepository{subscription: model.WebhookSubscription{
+		ID:          1,
+		WorkspaceID: testWorkspaceID,
+		Events:      "link.created,link.deleted",
+		Active:      true,
+	}}
+	s := NewWebhookService(repo, &http.Client{Timeout: time.Second}, nil, &fakeAuditService{}, 3)
+
+	events := []model.OutboxEvent{
+		{ID: 10, WorkspaceID: testWorkspaceID, AggregateType: model.AggregateLink, EventType: model.EventLinkCreated, Payload: model.JSON(`{"id":1}`)},
+		{ID: 11, WorkspaceID: testWorkspaceID, AggregateType: model.AggregateLink, EventType: model.EventLinkUpdated, Payload: model.JSON(`{"id":1}`)},
+		{ID: 12, WorkspaceID: testWorkspaceID, AggregateType: model.AggregateLink, EventType: model.EventLinkDeleted, Payload: model.JSON(`{"id":1}`)},
+	}
+
+	// a failing handler gets the same events relayed again
+	for i := 0; i < 2; i++ {
+		if err := s.HandleLinkEvents(context.Background(), events); err != nil {
+			t.Fatalf("HandleLinkEvents: %v", err)
+		}
+	}
+
+	if len(repo.deliveries) != 2 {
+		t.Fatalf("queued %d deliveries, want 2", len(repo.deliveries))
+	}
+	for i, want := range []model.WebhookEvent{model.WebhookLinkCreated, model.WebhookLinkDeleted} {
+		if got := repo.deliveries[i].Event; got != want {
+			t.Errorf("delivery %d event = %s, want %s", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"url_shortener/internal/model"
+	"url_shortener/internal/repository"
+	"url_shortener/internal/requestctx"
+)
+
+// interface for workspace operations
+type WorkspaceService interface {
+	CreateWorkspace(ctx context.Context, req model.CreateWorkspaceRequest) (*model.Workspace, error)
+	ListWorkspaces(ctx context.Context) ([]model.Workspace, error)
+	GetMembership(ctx context.Context, workspaceID uint, userID string) (*model.Membership, error)
+	ListMembers(ctx context.Context) ([]model.Membership, error)
+	InviteMember(ctx context.Context, req model.InviteMemberRequest) (*model.Membership, error)
+	ChangeMemberRole(ctx context.Context, userID string, req model.UpdateMemberRequest) (*model.Membership, error)
+	RemoveMember(ctx context.Context, userID string) error
+}
+
+// implements WorkspaceService interface
+type WorkspaceServiceImpl struct {
+	workspaceRepo repository.WorkspaceRepository
+	auditService  AuditService
+}
+
+// create a new workspace service
+func NewWorkspaceService(workspaceRepo repository.WorkspaceRepository, auditService AuditService) WorkspaceService {
+	return &WorkspaceServiceImpl{
+		workspaceRepo: workspaceRepo,
+		auditService:  auditService,
+	}
+}
+
+// CreateWorkspace creates a workspace owned by the calling user
+func (s *WorkspaceServiceImpl) CreateWorkspace(ctx context.Context, req model.CreateWorkspaceRequest) (*model.Workspace, error) {
+	workspace := &model.Workspace{Name: req.Name}
+
+	if err := s.workspaceRepo.CreateWithOwner(ctx, workspace, requestctx.UserID(ctx)); err != nil {
+		return nil, fmt.Errorf("failed to create workspace: %w", err)
+	}
+
+	s.auditService.Record(ctx, model.AuditEvent{
+		WorkspaceID: workspace.ID,
+		Action:      model.AuditWorkspaceCreated,
+		TargetType:  model.AuditTargetWorkspace,
+		TargetID:    strconv.FormatUint(uint64(workspace.ID), 10),
+	}, nil, workspace)
+
+	return workspace, nil
+}
+
+// ListWorkspaces lists the workspaces of the calling user
+func (s *WorkspaceServiceImpl) ListWorkspaces(ctx context.Context) ([]model.Workspace, error) {
+	return s.workspaceRepo.FindAllByUser(ctx, requestctx.UserID(ctx))
+}
+
+// GetMembership gets the membership of a user in a workspace
+func (s *WorkspaceServiceImpl) GetMembership(ctx context.Context, workspaceID uint, userID string) (*model.Membership, error) {
+	return s.workspaceRepo.FindMembership(ctx, workspaceID, userID)
+}
+
+// ListMembers lists the members of the active workspace
+func (s *WorkspaceServiceImpl) ListMembers(ctx context.Context) ([]model.Membership, error) {
+	return s.workspaceRepo.FindMembers(ctx, requestctx.WorkspaceID(ctx))
+}
+
+// InviteMember adds a user to the active workspace
+func (s *WorkspaceServiceImpl) InviteMember(ctx context.Context, req model.InviteMemberRequest) (*model.Membership, error) {
+	workspaceID := requestctx.WorkspaceID(ctx)
+	if err := checkCanGrant(ctx, req.Role); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.workspaceRepo.FindMembership(ctx, workspaceID, req.UserID); err == nil {
+		return nil, fmt.Errorf("user %s is already a member", req.UserID)
+	}
+
+	membership := &model.Membership{
+		WorkspaceID: workspaceID,
+		UserID:      req.UserID,
+		Role:        req.Role,
+		InvitedBy:   requestctx.UserID(ctx),
+	}
+
+	if err := s.workspaceRepo.CreateMembership(ctx, membership); err != nil {
+		return nil, fmt.Errorf("failed to invite member: %w", err)
+	}
+
+	s.auditService.Record(ctx, memberAuditEvent(model.AuditMemberInvited, membership), nil, membership)
+
+	return membership, nil
+}
+
+// ChangeMemberRole changes the role of a member of the active workspace
+func (s *WorkspaceServiceImpl) ChangeMemberRole(ctx context.Context, userID string, req model.UpdateMemberRequest) (*model.Membership, error) {
+	membership, err := s.workspaceRepo.FindMembership(ctx, requestctx.WorkspaceID(ctx), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkCanGrant(ctx, membership.Role); err != nil {
+		return nil, err
+	}
+	if err := checkCanGrant(ctx, req.Role); err != nil {
+		return nil, err
+	}
+	if membership.Role == model.RoleOwner && req.Role != model.RoleOwner {
+		if err := s.checkNotLastOwner(ctx, membership); err != nil {
+			return nil, err
+		}
+	}
+
+	before := *membership
+	membership.Role = req.Role
+	if err := s.workspaceRepo.UpdateMembership(ctx, membership); err != nil {
+		return nil, fmt.Errorf("failed to update member: %w", err)
+	}
+
+	s.auditService.Record(ctx, memberAuditEvent(model.AuditMemberRoleChanged, membership), &before, membership)
+
+	return membership, nil
+}
+
+// RemoveMember removes a member from the active workspace
+func (s *WorkspaceServiceImpl) RemoveMember(ctx context.Context, userID string) error {
+	membership, err := s.workspaceRepo.FindMembership(ctx, requestctx.WorkspaceID(ctx), userID)
+	if err != nil {
+		return err
+	}
+
+	if err := checkCanGrant(ctx, membership.Role); err != nil {
+		return err
+	}
+	if membership.Role == model.RoleOwner {
+		if err := s.checkNotLastOwner(ctx, membership); err != nil {
+			return err
+		}
+	}
+
+	if err := s.workspaceRepo.DeleteMembership(ctx, membership); err != nil {
+		return fmt.Errorf("failed to remove member: %w", err)
+	}
+
+	s.auditService.Record(ctx, memberAuditEvent(model.AuditMemberRemoved, membership), membership, nil)
+
+	return nil
+}
+
+// memberAuditEvent returns the audit event of an action on a membership, the
+// target being the member's user ID
+func memberAuditEvent(action model.AuditAction, membership *model.Membership) model.AuditEvent {
+	return model.AuditEvent{
+		WorkspaceID: membership.WorkspaceID,
+		Action:      action,
+		TargetType:  model.AuditTargetMember,
+		TargetID:    membership.UserID,
+	}
+}
+
+// checkCanGrant makes sure the caller may manage members having role,
+// only owners may manage other owners
+func checkCanGrant(ctx context.Context, role model.Role) error {
+	if role == model.RoleOwner && requestctx.Role(ctx) != model.RoleOwner {
+		return fmt.Errorf("%w: only owners can manage owners", ErrForbidden)
+	}
+
+	return nil
+}
+
+// checkNotLastOwner prevents a workspace from losing its last owner
+func (s *WorkspaceServiceImpl) checkNotLastOwner(ctx context.Context, membership *model.Membership) error {
+	owners, err := s.workspaceRepo.CountMembersWithRole(ctx, membership.WorkspaceID, model.RoleOwner)
+	if err != nil {
+		return fmt.Errorf("error counting owners: %w", err)
+	}
+	if owners <= 1 {
+		return fmt.Errorf("a workspace must keep at least one owner")
+	}
+
+	return nil
+}
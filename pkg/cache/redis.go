@@ -3,12 +3,16 @@ package cache
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/go-redis/redis/v8"
 )
 
+// ErrNotFound is returned when a key is not set, unlike redis being unreachable
+var ErrNotFound = errors.New("key not found")
+
 // redisclient represnt the redis client
 type RedisClient struct {
 	client *redis.Client
@@ -68,7 +72,7 @@ func (r *RedisClient) Get(ctx context.Context, key string) (string, error) {
 	result, err := r.client.Get(ctx, key).Result()
 	if err != nil {
 		if err == redis.Nil {
-			return "", fmt.Errorf("key not found")
+			return "", ErrNotFound
 		}
 		return "", fmt.Errorf("failed to get value: %w", err)
 	}
@@ -81,7 +85,7 @@ func (r *RedisClient) GetObject(ctx context.Context, key string, obj interface{}
 	data, err := r.client.Get(ctx, key).Bytes()
 	if err != nil {
 		if err == redis.Nil {
-			return fmt.Errorf("key not found")
+			return ErrNotFound
 		}
 		return fmt.Errorf("failed to get value: %w", err)
 	}
@@ -107,3 +111,103 @@ func (r *RedisClient) Increment(ctx context.Context, key string) (int64, error)
 func (r *RedisClient) SetWithTTL(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
 	return r.Set(ctx, key, value, ttl)
 }
+
+// increment key value by n
+func (r *RedisClient) IncrementBy(ctx context.Context, key string, n int64) (int64, error) {
+	return r.client.IncrBy(ctx, key, n).Result()
+}
+
+// setMaxScript raises the integer value of a key to ARGV[1] when it is lower
+// or unset, and returns the resulting value
+var setMaxScript = redis.NewScript(`
+local current = tonumber(redis.call('GET', KEYS[1]))
+local value = tonumber(ARGV[1])
+if current == nil or current < value then
+	redis.call('SET', KEYS[1], value)
+	return value
+end
+return current
+`)
+
+// raise the integer value of a key to value, returning the value it ends up with
+func (r *RedisClient) SetMax(ctx context.Context, key string, value int64) (int64, error) {
+	return setMaxScript.Run(ctx, r.client, []string{key}, value).Int64()
+}
+
+// incrementExistingScript increments a key only when it exists, returning -1 otherwise
+var incrementExistingScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return -1
+end
+return redis.call("INCR", KEYS[1])
+`)
+
+// increment the integer value of a key, reporting false without creating the key when it does not exist
+func (r *RedisClient) IncrementExisting(ctx context.Context, key string) (int64, bool, error) {
+	value, err := incrementExistingScript.Run(ctx, r.client, []string{key}).Int64()
+	if err != nil {
+		return 0, false, err
+	}
+	if value < 0 {
+		return 0, false, nil
+	}
+
+	return value, true, nil
+}
+
+// store a value with expiration unless the key exists, reporting whether it was stored
+func (r *RedisClient) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	return r.client.SetNX(ctx, key, value, expiration).Result()
+}
+
+// scan the keys matching a glob pattern
+func (r *RedisClient) ScanKeys(ctx context.Context, pattern string) ([]string, error) {
+	var keys []string
+	iter := r.client.Scan(ctx, 0, pattern, 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// append an entry to a stream, trimmed to about maxLen entries when maxLen is positive
+func (r *RedisClient) AddToStream(ctx context.Context, stream string, maxLen int64, values map[string]interface{}) error {
+	return r.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: stream,
+		MaxLen: maxLen,
+		Approx: maxLen > 0,
+		Values: values,
+	}).Err()
+}
+
+// publish a message on a channel
+func (r *RedisClient) Publish(ctx context.Context, channel string, message interface{}) error {
+	return r.client.Publish(ctx, channel, message).Err()
+}
+
+// subscribe to a channel, returning its messages and a function ending the subscription
+func (r *RedisClient) Subscribe(ctx context.Context, channel string) (<-chan string, func() error, error) {
+	pubsub := r.client.Subscribe(ctx, channel)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, nil, fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	messages := make(chan string)
+	go func() {
+		defer close(messages)
+		for message := range pubsub.Channel() {
+			select {
+			case messages <- message.Payload:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return messages, pubsub.Close, nil
+}
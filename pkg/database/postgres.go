@@ -64,3 +64,13 @@ func (p *PostgresDB) Close() error {
 func (p *PostgresDB) Migrate(models ...interface{}) error {
 	return p.DB.AutoMigrate(models...)
 }
+
+// DropIndex drops an index of a model's table if it exists
+func (p *PostgresDB) DropIndex(model interface{}, name string) error {
+	migrator := p.DB.Migrator()
+	if !migrator.HasIndex(model, name) {
+		return nil
+	}
+
+	return migrator.DropIndex(model, name)
+}
@@ -0,0 +1,103 @@
+package dns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+)
+
+// Resolver looks up DNS TXT records. Names without records resolve to no
+// records and no error, so that errors only report failed lookups
+type Resolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// NetResolver resolves TXT records through the system resolver
+type NetResolver struct {
+	resolver *net.Resolver
+}
+
+// NewNetResolver creates a resolver backed by the system resolver
+func NewNetResolver() *NetResolver {
+	return &NetResolver{resolver: net.DefaultResolver}
+}
+
+// LookupTXT returns the TXT records of name
+func (r *NetResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	records, err := r.resolver.LookupTXT(ctx, name)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to lookup TXT records of %s: %w", name, err)
+	}
+
+	return records, nil
+}
+
+// MemoryResolver serves TXT records from memory, for tests and local setups
+type MemoryResolver struct {
+	mu      sync.Mutex
+	records map[string][]string
+	err     error
+	lookups int
+}
+
+// NewMemoryResolver creates an empty in-memory resolver
+func NewMemoryResolver() *MemoryResolver {
+	return &MemoryResolver{records: make(map[string][]string)}
+}
+
+// SetTXT replaces the TXT records of name
+func (r *MemoryResolver) SetTXT(name string, values ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.records[canonical(name)] = values
+}
+
+// RemoveTXT deletes the TXT records of name
+func (r *MemoryResolver) RemoveTXT(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.records, canonical(name))
+}
+
+// Fail makes every lookup fail with err, as during a DNS outage, until called with nil
+func (r *MemoryResolver) Fail(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.err = err
+}
+
+// Lookups returns the number of lookups made, failed ones included
+func (r *MemoryResolver) Lookups() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.lookups
+}
+
+// LookupTXT returns the TXT records of name
+func (r *MemoryResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lookups++
+	if r.err != nil {
+		return nil, fmt.Errorf("failed to lookup TXT records of %s: %w", name, r.err)
+	}
+
+	return append([]string(nil), r.records[canonical(name)]...), nil
+}
+
+// canonical lowercases a DNS name and strips its trailing dot
+func canonical(name string) string {
+	return strings.TrimSuffix(strings.ToLower(name), ".")
+}
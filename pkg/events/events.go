@@ -0,0 +1,23 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Event is a domain event published to downstream consumers
+type Event struct {
+	ID            string          `json:"id"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	Payload       json.RawMessage `json:"payload"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+}
+
+// EventPublisher publishes events. Publishing is at least once, so consumers
+// must tolerate duplicates, recognizable by their ID
+type EventPublisher interface {
+	Publish(ctx context.Context, events ...Event) error
+}
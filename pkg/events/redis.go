@@ -0,0 +1,46 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"url_shortener/pkg/cache"
+)
+
+// RedisStreamPublisher appends events to a Redis stream, trimmed to about maxLen entries
+type RedisStreamPublisher struct {
+	client *cache.RedisClient
+	stream string
+	maxLen int64
+}
+
+// NewRedisStreamPublisher creates a publisher appending to stream, keeping
+// about maxLen entries when maxLen is positive
+func NewRedisStreamPublisher(client *cache.RedisClient, stream string, maxLen int64) *RedisStreamPublisher {
+	return &RedisStreamPublisher{
+		client: client,
+		stream: stream,
+		maxLen: maxLen,
+	}
+}
+
+// Publish appends the events to the stream in order
+func (p *RedisStreamPublisher) Publish(ctx context.Context, events ...Event) error {
+	for _, event := range events {
+		values := map[string]interface{}{
+			"id":             event.ID,
+			"type":           event.Type,
+			"aggregate_type": event.AggregateType,
+			"aggregate_id":   event.AggregateID,
+			"payload":        string(event.Payload),
+			"occurred_at":    event.OccurredAt.UTC().Format(time.RFC3339Nano),
+		}
+
+		if err := p.client.AddToStream(ctx, p.stream, p.maxLen, values); err != nil {
+			return fmt.Errorf("failed to publish event %s: %w", event.ID, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package linkcheck
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Result is the outcome of checking a URL
+type Result struct {
+	StatusCode int
+	Latency    time.Duration
+	FinalURL   string
+	Err        error
+}
+
+// OK reports whether the URL still resolves to a page. Statuses telling the
+// page exists but is restricted or rate limited count as healthy
+func (r Result) OK() bool {
+	if r.Err != nil {
+		return false
+	}
+
+	switch r.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusTooManyRequests:
+		return true
+	}
+
+	return r.StatusCode < 400
+}
+
+// Checker checks that URLs still resolve, following their redirects
+type Checker struct {
+	client *http.Client
+}
+
+// NewChecker creates a checker sending its requests with client
+func NewChecker(client *http.Client) *Checker {
+	return &Checker{client: client}
+}
+
+// Check requests a URL with HEAD, retrying with GET when the server fails the
+// HEAD request, as some servers do not implement it
+func (c *Checker) Check(ctx context.Context, rawURL string) Result {
+	result := c.request(ctx, http.MethodHead, rawURL)
+	if !result.OK() {
+		result = c.request(ctx, http.MethodGet, rawURL)
+	}
+
+	return result
+}
+
+// request sends a single request, measuring its latency including redirects
+func (c *Checker) request(ctx context.Context, method, rawURL string) Result {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return Result{Err: fmt.Errorf("invalid url: %w", err)}
+	}
+	req.Header.Set("User-Agent", "url-shortener-linkcheck/1.0")
+
+	start := time.Now()
+	resp, err := c.client.Do(req)
+	latency := time.Since(start)
+	if err != nil {
+		return Result{Latency: latency, Err: err}
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	return Result{
+		StatusCode: resp.StatusCode,
+		Latency:    latency,
+		FinalURL:   resp.Request.URL.String(),
+	}
+}
@@ -0,0 +1,195 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+// Metadata describes a web page, as shown in link previews
+type Metadata struct {
+	Title       string
+	Description string
+	FaviconURL  string
+	ImageURL    string
+}
+
+// maxFieldLength caps the length of the text fields kept from a page
+const maxFieldLength = 500
+
+// Fetcher fetches and parses the metadata of web pages
+type Fetcher struct {
+	client   *http.Client
+	maxBytes int64
+}
+
+// NewFetcher creates a fetcher sending its requests with client and reading
+// at most maxBytes of every page. The client is expected to enforce the time
+// limit and to refuse connecting to private addresses
+func NewFetcher(client *http.Client, maxBytes int64) *Fetcher {
+	return &Fetcher{
+		client:   client,
+		maxBytes: maxBytes,
+	}
+}
+
+// Fetch fetches a page and returns its title, description, favicon and Open
+// Graph image, Open Graph properties taking precedence over plain HTML
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (*Metadata, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %w", err)
+	}
+	req.Header.Set("User-Agent", "url-shortener-metadata/1.0")
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("failed to fetch %s: status %d", rawURL, resp.StatusCode)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil && mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, fmt.Errorf("failed to fetch %s: not an html page but %s", rawURL, mediaType)
+	}
+
+	body, err := charset.NewReader(io.LimitReader(resp.Body, f.maxBytes), contentType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", rawURL, err)
+	}
+
+	return Parse(body, resp.Request.URL)
+}
+
+// Parse parses the metadata of an HTML page served from base, resolving
+// relative URLs against it. A truncated page yields what its head held
+func Parse(r io.Reader, base *url.URL) (*Metadata, error) {
+	var (
+		meta                         Metadata
+		ogTitle, ogDescription, icon string
+		inTitle                      bool
+	)
+
+	tokenizer := html.NewTokenizer(r)
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			if err := tokenizer.Err(); err != io.EOF && err != io.ErrUnexpectedEOF {
+				return nil, fmt.Errorf("failed to parse page: %w", err)
+			}
+			return meta.finish(ogTitle, ogDescription, icon, base), nil
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			switch token.Data {
+			case "title":
+				inTitle = meta.Title == ""
+			case "meta":
+				key := strings.ToLower(attr(token, "property"))
+				if key == "" {
+					key = strings.ToLower(attr(token, "name"))
+				}
+				content := attr(token, "content")
+				switch key {
+				case "og:title":
+					ogTitle = content
+				case "og:description":
+					ogDescription = content
+				case "og:image", "og:image:url", "og:image:secure_url":
+					if meta.ImageURL == "" {
+						meta.ImageURL = content
+					}
+				case "description":
+					meta.Description = content
+				}
+			case "link":
+				rel := strings.ToLower(attr(token, "rel"))
+				if icon == "" || rel == "icon" {
+					for _, value := range strings.Fields(rel) {
+						if value == "icon" || value == "apple-touch-icon" {
+							icon = attr(token, "href")
+							break
+						}
+					}
+				}
+			case "body":
+				// metadata lives in the head, the rest of the page is not needed
+				return meta.finish(ogTitle, ogDescription, icon, base), nil
+			}
+		case html.TextToken:
+			if inTitle {
+				meta.Title += string(tokenizer.Text())
+			}
+		case html.EndTagToken:
+			if name, _ := tokenizer.TagName(); string(name) == "title" {
+				inTitle = false
+			}
+		}
+	}
+}
+
+// finish applies the Open Graph overrides, resolves the URLs and trims the fields
+func (m *Metadata) finish(ogTitle, ogDescription, icon string, base *url.URL) *Metadata {
+	if ogTitle != "" {
+		m.Title = ogTitle
+	}
+	if ogDescription != "" {
+		m.Description = ogDescription
+	}
+	if icon == "" {
+		icon = "/favicon.ico"
+	}
+
+	m.Title = truncate(strings.Join(strings.Fields(m.Title), " "))
+	m.Description = truncate(strings.Join(strings.Fields(m.Description), " "))
+	m.FaviconURL = resolve(base, icon)
+	m.ImageURL = resolve(base, m.ImageURL)
+
+	return m
+}
+
+// attr returns the value of an attribute of a token
+func attr(token html.Token, name string) string {
+	for _, a := range token.Attr {
+		if strings.EqualFold(a.Key, name) {
+			return strings.TrimSpace(a.Val)
+		}
+	}
+
+	return ""
+}
+
+// resolve resolves a reference against base, keeping only http(s) URLs
+func resolve(base *url.URL, ref string) string {
+	if ref == "" {
+		return ""
+	}
+
+	u, err := base.Parse(ref)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+
+	return u.String()
+}
+
+// truncate caps a text field at maxFieldLength runes
+func truncate(s string) string {
+	runes := []rune(s)
+	if len(runes) <= maxFieldLength {
+		return s
+	}
+
+	return string(runes[:maxFieldLength])
+}
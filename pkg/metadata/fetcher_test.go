@@ -0,0 +1,183 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"url_shortener/pkg/safehttp"
+)
+
+// mustParseURL parses a URL or fails the test
+func mustParseURL(t *testing.T, rawURL string) *url.URL {
+	t.Helper()
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return u
+}
+
+func TestParsePrefersOpenGraph(t *testing.T) {
+	page := `<html><head>
+		<title>Plain title</title>
+		<meta name="description" content="Plain description">
+		<meta property="og:title" content="OG title">
+		<meta property="og:description" content="OG description">
+		<meta property="og:image" content="/first.png">
+		<meta property="og:image" content="/second.png">
+	</head><body></body></html>`
+
+	meta, err := Parse(strings.NewReader(page), mustParseURL(t, "https://example.com/post/1"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	if meta.Title != "OG title" {
+		t.Errorf("Title = %q, want %q", meta.Title, "OG title")
+	}
+	if meta.Description != "OG description" {
+		t.Errorf("Description = %q, want %q", meta.Description, "OG description")
+	}
+	if meta.ImageURL != "https://example.com/first.png" {
+		t.Errorf("ImageURL = %q, want the first og:image", meta.ImageURL)
+	}
+}
+
+func TestParseFallsBackToPlainHTML(t *testing.T) {
+	page := `<html><head><title>  Plain
+		title </title><meta name="description" content="Plain description"></head></html>`
+
+	meta, err := Parse(strings.NewReader(page), mustParseURL(t, "https://example.com/"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	if meta.Title != "Plain title" {
+		t.Errorf("Title = %q, want %q", meta.Title, "Plain title")
+	}
+	if meta.Description != "Plain description" {
+		t.Errorf("Description = %q, want %q", meta.Description, "Plain description")
+	}
+}
+
+func TestParseResolvesFavicon(t *testing.T) {
+	tests := []struct {
+		name string
+		page string
+		want string
+	}{
+		{
+			name: "relative icon",
+			page: `<head><link rel="icon" href="../img/icon.png"></head>`,
+			want: "https://example.com/img/icon.png",
+		},
+		{
+			name: "icon preferred over apple-touch-icon",
+			page: `<head><link rel="apple-touch-icon" href="/touch.png"><link rel="icon" href="/icon.png"></head>`,
+			want: "https://example.com/icon.png",
+		},
+		{
+			name: "protocol-relative icon",
+			page: `<head><link rel="shortcut icon" href="//cdn.example.net/icon.ico"></head>`,
+			want: "https://cdn.example.net/icon.ico",
+		},
+		{
+			name: "default favicon",
+			page: `<head><title>No icon</title></head>`,
+			want: "https://example.com/favicon.ico",
+		},
+		{
+			name: "non-http icon dropped",
+			page: `<head><link rel="icon" href="javascript:alert(1)"></head>`,
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta, err := Parse(strings.NewReader(tt.page), mustParseURL(t, "https://example.com/blog/post"))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if meta.FaviconURL != tt.want {
+				t.Errorf("FaviconURL = %q, want %q", meta.FaviconURL, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchReadsAtMostMaxBytes(t *testing.T) {
+	head := `<html><head><title>Kept</title>`
+	page := head + strings.Repeat("<!-- padding -->", 100) + `<meta name="description" content="Dropped"></head></html>`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	fetcher := NewFetcher(server.Client(), int64(len(head)+64))
+	meta, err := fetcher.Fetch(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+
+	if meta.Title != "Kept" {
+		t.Errorf("Title = %q, want %q", meta.Title, "Kept")
+	}
+	if meta.Description != "" {
+		t.Errorf("Description = %q, want it cut off by maxBytes", meta.Description)
+	}
+}
+
+func TestFetchRejectsNonHTML(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Write([]byte("%PDF-1.7"))
+	}))
+	defer server.Close()
+
+	fetcher := NewFetcher(server.Client(), 1<<20)
+	if _, err := fetcher.Fetch(context.Background(), server.URL); err == nil {
+		t.Fatal("Fetch accepted a PDF document")
+	}
+}
+
+func TestFetchRejectsErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	fetcher := NewFetcher(server.Client(), 1<<20)
+	if _, err := fetcher.Fetch(context.Background(), server.URL); err == nil {
+		t.Fatal("Fetch accepted a 404 response")
+	}
+}
+
+func TestFetchRefusesLoopbackWithSafeClient(t *testing.T) {
+	requested := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = true
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<title>Internal</title>"))
+	}))
+	defer server.Close()
+
+	fetcher := NewFetcher(safehttp.NewClient(5*time.Second), 1<<20)
+	_, err := fetcher.Fetch(context.Background(), server.URL)
+	if !errors.Is(err, safehttp.ErrPrivateAddress) {
+		t.Fatalf("Fetch error = %v, want ErrPrivateAddress", err)
+	}
+	if requested {
+		t.Error("the loopback server was requested")
+	}
+}
@@ -0,0 +1,197 @@
+package qrcode
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strconv"
+	"strings"
+
+	goqrcode "github.com/skip2/go-qrcode"
+)
+
+const (
+	// DefaultSize is the default width and height of a QR code in pixels
+	DefaultSize = 256
+
+	// DefaultMargin is the default quiet zone around a QR code, in modules
+	DefaultMargin = 4
+
+	// FormatPNG renders QR codes as PNG images
+	FormatPNG = "png"
+
+	// FormatSVG renders QR codes as SVG documents
+	FormatSVG = "svg"
+)
+
+// Options controls how a QR code is rendered
+type Options struct {
+	Size       int
+	Level      string
+	Margin     int
+	Foreground color.RGBA
+	Background color.RGBA
+}
+
+// DefaultOptions returns black on white options at the default size
+func DefaultOptions() Options {
+	return Options{
+		Size:       DefaultSize,
+		Level:      "M",
+		Margin:     DefaultMargin,
+		Foreground: color.RGBA{A: 0xff},
+		Background: color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+	}
+}
+
+// Render encodes content as a QR code image in the given format
+func Render(content, format string, opts Options) ([]byte, error) {
+	modules, err := encode(content, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case FormatPNG:
+		return renderPNG(modules, opts)
+	case FormatSVG:
+		return renderSVG(modules, opts), nil
+	default:
+		return nil, fmt.Errorf("unsupported QR code format %q", format)
+	}
+}
+
+// ContentType returns the MIME type of a QR code format
+func ContentType(format string) string {
+	if format == FormatSVG {
+		return "image/svg+xml"
+	}
+
+	return "image/png"
+}
+
+// ParseColor parses a hex color such as "1a2b3c", "#1a2b3c" or "#abc"
+func ParseColor(s string) (color.RGBA, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return color.RGBA{}, fmt.Errorf("invalid color %q", s)
+	}
+
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("invalid color %q", s)
+	}
+
+	return color.RGBA{
+		R: uint8(value >> 16),
+		G: uint8(value >> 8),
+		B: uint8(value),
+		A: 0xff,
+	}, nil
+}
+
+// encode builds the module matrix of the QR code, surrounded by the margin
+func encode(content string, opts Options) ([][]bool, error) {
+	level, err := recoveryLevel(opts.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	q, err := goqrcode.New(content, level)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode QR code: %w", err)
+	}
+	q.DisableBorder = true
+
+	symbol := q.Bitmap()
+	size := len(symbol) + 2*opts.Margin
+	modules := make([][]bool, size)
+	for y := range modules {
+		modules[y] = make([]bool, size)
+	}
+	for y, row := range symbol {
+		copy(modules[y+opts.Margin][opts.Margin:], row)
+	}
+
+	return modules, nil
+}
+
+// recoveryLevel maps an error-correction level letter to the encoder level
+func recoveryLevel(level string) (goqrcode.RecoveryLevel, error) {
+	switch strings.ToUpper(level) {
+	case "L":
+		return goqrcode.Low, nil
+	case "", "M":
+		return goqrcode.Medium, nil
+	case "Q":
+		return goqrcode.High, nil
+	case "H":
+		return goqrcode.Highest, nil
+	default:
+		return 0, fmt.Errorf("invalid error correction level %q", level)
+	}
+}
+
+// renderPNG draws the modules at a whole number of pixels per module, centering
+// the code when the requested size is not a multiple of the module count
+func renderPNG(modules [][]bool, opts Options) ([]byte, error) {
+	count := len(modules)
+	size := opts.Size
+	if size < count {
+		size = count
+	}
+	scale := size / count
+	offset := (size - scale*count) / 2
+
+	img := image.NewPaletted(image.Rect(0, 0, size, size), color.Palette{opts.Background, opts.Foreground})
+	for y, row := range modules {
+		for x, dark := range row {
+			if !dark {
+				continue
+			}
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale; dx++ {
+					img.SetColorIndex(offset+x*scale+dx, offset+y*scale+dy, 1)
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode png: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// renderSVG draws the modules as a single path scaled to the requested size
+func renderSVG(modules [][]bool, opts Options) []byte {
+	count := len(modules)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		opts.Size, opts.Size, count, count)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="%s"/>`, count, count, hexColor(opts.Background))
+	fmt.Fprintf(&buf, `<path fill="%s" d="`, hexColor(opts.Foreground))
+	for y, row := range modules {
+		for x, dark := range row {
+			if dark {
+				fmt.Fprintf(&buf, "M%d %dh1v1h-1z", x, y)
+			}
+		}
+	}
+	buf.WriteString(`"/></svg>`)
+
+	return buf.Bytes()
+}
+
+// hexColor formats a color as #rrggbb
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
@@ -0,0 +1,64 @@
+package safehttp
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"url_shortener/pkg/safety"
+)
+
+// ErrPrivateAddress is returned when a request would connect to an address
+// that is not publicly routable
+var ErrPrivateAddress = errors.New("connection to non-public address refused")
+
+// maxRedirects is the number of redirects a client follows
+const maxRedirects = 10
+
+// NewClient creates an HTTP client for fetching user-supplied URLs. It only
+// connects to public addresses, checked after DNS resolution so that names
+// resolving to internal hosts and redirects to them are refused too
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || safety.IsPrivateIP(ip) {
+				return fmt.Errorf("%w: %s", ErrPrivateAddress, host)
+			}
+			return nil
+		},
+	}
+
+	transport := &http.Transport{
+		DialContext: dialer.DialContext,
+		// proxies would connect on our behalf, bypassing the address check
+		Proxy:                 nil,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ResponseHeaderTimeout: timeout,
+	}
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return fmt.Errorf("redirect to unsupported scheme %q", req.URL.Scheme)
+			}
+			return nil
+		},
+	}
+}
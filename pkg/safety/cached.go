@@ -0,0 +1,68 @@
+package safety
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"time"
+
+	"url_shortener/pkg/cache"
+)
+
+// reputationCachePrefix prefixes the cache keys of verdicts
+const reputationCachePrefix = "reputation:"
+
+// CachedReputationProvider caches the verdicts of another provider in Redis,
+// only asking it about the URLs without a cached verdict
+type CachedReputationProvider struct {
+	provider ReputationProvider
+	cache    *cache.RedisClient
+	ttl      time.Duration
+}
+
+// NewCachedReputationProvider wraps provider, caching its verdicts for ttl
+func NewCachedReputationProvider(provider ReputationProvider, cache *cache.RedisClient, ttl time.Duration) *CachedReputationProvider {
+	return &CachedReputationProvider{
+		provider: provider,
+		cache:    cache,
+		ttl:      ttl,
+	}
+}
+
+// Lookup returns the cached verdicts of urls, looking up the missing ones
+func (p *CachedReputationProvider) Lookup(ctx context.Context, urls []string) (map[string]Verdict, error) {
+	verdicts := make(map[string]Verdict, len(urls))
+	var missing []string
+	for _, u := range urls {
+		var verdict Verdict
+		if err := p.cache.GetObject(ctx, reputationCacheKey(u), &verdict); err != nil {
+			missing = append(missing, u)
+			continue
+		}
+		verdicts[u] = verdict
+	}
+	if len(missing) == 0 {
+		return verdicts, nil
+	}
+
+	fresh, err := p.provider.Lookup(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+
+	for u, verdict := range fresh {
+		verdicts[u] = verdict
+		if err := p.cache.Set(ctx, reputationCacheKey(u), verdict, p.ttl); err != nil {
+			log.Printf("error caching reputation of %s: %v", u, err)
+		}
+	}
+
+	return verdicts, nil
+}
+
+// reputationCacheKey returns the cache key of the verdict of a URL
+func reputationCacheKey(u string) string {
+	sum := sha256.Sum256([]byte(u))
+	return reputationCachePrefix + hex.EncodeToString(sum[:])
+}
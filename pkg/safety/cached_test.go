@@ -0,0 +1,117 @@
+package safety
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"url_shortener/pkg/cache"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+// newCachedProvider returns a Safe Browsing client of stub cached in an
+// in-memory redis server
+func newCachedProvider(t *testing.T, stub *safeBrowsingStub) (*CachedReputationProvider, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client, err := cache.NewRedisClient(server.Addr(), "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	provider := NewSafeBrowsingClient(stub.URL, "test-key", "tests", stub.Client())
+
+	return NewCachedReputationProvider(provider, client, time.Hour), server
+}
+
+func TestCachedLookupAsksOnlyForMissingURLs(t *testing.T) {
+	stub := newSafeBrowsingStub(t)
+	provider, _ := newCachedProvider(t, stub)
+	ctx := context.Background()
+
+	if _, err := provider.Lookup(ctx, []string{"https://example.com/", "https://malware.test/"}); err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+
+	verdicts, err := provider.Lookup(ctx, []string{"https://malware.test/", "https://example.org/"})
+	if err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+
+	if verdicts["https://malware.test/"].Threat != "MALWARE" {
+		t.Errorf("cached verdict = %+v, want MALWARE", verdicts["https://malware.test/"])
+	}
+	if verdicts["https://example.org/"].Unsafe() {
+		t.Errorf("example.org verdict = %+v, want safe", verdicts["https://example.org/"])
+	}
+
+	want := [][]string{
+		{"https://example.com/", "https://malware.test/"},
+		{"https://example.org/"},
+	}
+	if got := stub.lookups(); !reflect.DeepEqual(got, want) {
+		t.Errorf("stub lookups = %v, want %v", got, want)
+	}
+}
+
+func TestCachedLookupServedFromCache(t *testing.T) {
+	stub := newSafeBrowsingStub(t)
+	provider, _ := newCachedProvider(t, stub)
+	ctx := context.Background()
+
+	if _, err := provider.Lookup(ctx, []string{"https://malware.test/"}); err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+
+	// the service going down does not matter while the verdict is cached
+	stub.failWith(http.StatusServiceUnavailable)
+	verdicts, err := provider.Lookup(ctx, []string{"https://malware.test/"})
+	if err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	if verdicts["https://malware.test/"].Threat != "MALWARE" {
+		t.Errorf("verdict = %+v, want MALWARE", verdicts["https://malware.test/"])
+	}
+	if n := len(stub.lookups()); n != 1 {
+		t.Errorf("stub received %d lookups, want 1", n)
+	}
+}
+
+func TestCachedLookupExpires(t *testing.T) {
+	stub := newSafeBrowsingStub(t)
+	provider, server := newCachedProvider(t, stub)
+	ctx := context.Background()
+
+	if _, err := provider.Lookup(ctx, []string{"https://example.com/"}); err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+
+	server.FastForward(2 * time.Hour)
+	if _, err := provider.Lookup(ctx, []string{"https://example.com/"}); err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+
+	if n := len(stub.lookups()); n != 2 {
+		t.Errorf("stub received %d lookups, want 2 once the verdict expired", n)
+	}
+}
+
+func TestCachedLookupDoesNotCacheFailures(t *testing.T) {
+	stub := newSafeBrowsingStub(t)
+	provider, server := newCachedProvider(t, stub)
+	ctx := context.Background()
+
+	stub.failWith(http.StatusInternalServerError)
+	if _, err := provider.Lookup(ctx, []string{"https://example.com/"}); err == nil {
+		t.Fatal("Lookup succeeded on a failing service")
+	}
+
+	if keys := server.Keys(); len(keys) != 0 {
+		t.Errorf("cache holds %v after a failed lookup, want nothing", keys)
+	}
+}
@@ -0,0 +1,128 @@
+package safety
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Reasons a URL is blocked for
+const (
+	ReasonInvalid        = "invalid"
+	ReasonScheme         = "scheme"
+	ReasonPrivateAddress = "private_address"
+	ReasonHomograph      = "homograph"
+	ReasonBlocklist      = "blocklist"
+	ReasonPattern        = "pattern"
+)
+
+// allowedSchemes are the only schemes links may point to
+var allowedSchemes = map[string]bool{"http": true, "https": true}
+
+// BlockedError tells why a URL was blocked
+type BlockedError struct {
+	Reason string
+	Detail string
+}
+
+func (e *BlockedError) Error() string {
+	return fmt.Sprintf("url blocked (%s): %s", e.Reason, e.Detail)
+}
+
+// Checker blocks unsafe URLs: non-web schemes, private addresses, IDN homographs,
+// and the domains and patterns listed in local files, which are reloaded when
+// they change once Watch is called
+type Checker struct {
+	blocklistFile string
+	patternsFile  string
+
+	mu       sync.RWMutex
+	domains  map[string]bool
+	patterns []*regexp.Regexp
+
+	watcher *fsnotify.Watcher
+}
+
+// NewChecker creates a checker loading its domain blocklist and regex patterns
+// from the given files, one entry per line with # comments. An empty path
+// disables the corresponding list
+func NewChecker(blocklistFile, patternsFile string) (*Checker, error) {
+	c := &Checker{
+		blocklistFile: blocklistFile,
+		patternsFile:  patternsFile,
+		domains:       map[string]bool{},
+	}
+
+	if err := c.Reload(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Check returns a *BlockedError when rawURL must not be shortened
+func (c *Checker) Check(rawURL string) error {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return &BlockedError{Reason: ReasonInvalid, Detail: err.Error()}
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	if !allowedSchemes[scheme] {
+		return &BlockedError{Reason: ReasonScheme, Detail: fmt.Sprintf("scheme %q is not allowed", u.Scheme)}
+	}
+
+	host, err := normalizeHost(u.Hostname())
+	if err != nil {
+		return &BlockedError{Reason: ReasonInvalid, Detail: err.Error()}
+	}
+
+	if isPrivateHost(host.ascii) {
+		return &BlockedError{Reason: ReasonPrivateAddress, Detail: fmt.Sprintf("host %s is not publicly routable", host.ascii)}
+	}
+
+	if label, ok := homographLabel(host.unicode); ok {
+		return &BlockedError{Reason: ReasonHomograph, Detail: fmt.Sprintf("label %q mixes or imitates scripts", label)}
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, candidate := range []string{host.ascii, host.skeleton} {
+		if domain, ok := c.matchDomain(candidate); ok {
+			return &BlockedError{Reason: ReasonBlocklist, Detail: fmt.Sprintf("domain %s is blocklisted", domain)}
+		}
+	}
+
+	// patterns see the URL both as submitted and with its host in ASCII form
+	normalized := *u
+	normalized.Host = strings.Replace(u.Host, u.Hostname(), host.ascii, 1)
+	for _, pattern := range c.patterns {
+		if pattern.MatchString(rawURL) || pattern.MatchString(normalized.String()) {
+			return &BlockedError{Reason: ReasonPattern, Detail: fmt.Sprintf("url matches %s", pattern)}
+		}
+	}
+
+	return nil
+}
+
+// matchDomain reports whether host or one of its parent domains is blocklisted
+func (c *Checker) matchDomain(host string) (string, bool) {
+	for domain := host; domain != ""; {
+		if c.domains[domain] {
+			return domain, true
+		}
+
+		_, parent, found := strings.Cut(domain, ".")
+		if !found {
+			break
+		}
+		domain = parent
+	}
+
+	return "", false
+}
@@ -0,0 +1,199 @@
+package safety
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/idna"
+)
+
+// privateSuffixes are names that never resolve to a public host
+var privateSuffixes = []string{".localhost", ".local", ".internal", ".home.arpa"}
+
+// reservedNetworks are the IPv4 special-purpose ranges of RFC 6890 that are not
+// publicly routable and not covered by the net.IP predicates
+var reservedNetworks = []*net.IPNet{
+	mustParseCIDR("0.0.0.0/8"),     // "this" network
+	mustParseCIDR("100.64.0.0/10"), // carrier-grade NAT shared space
+	mustParseCIDR("192.0.0.0/24"),  // IETF protocol assignments
+	mustParseCIDR("198.18.0.0/15"), // benchmarking
+	mustParseCIDR("240.0.0.0/4"),   // reserved, including limited broadcast
+}
+
+// nat64Prefix is the well-known NAT64 prefix of RFC 6052, its addresses
+// embedding an IPv4 address in their last 32 bits
+var nat64Prefix = mustParseCIDR("64:ff9b::/96")
+
+// confusables maps Cyrillic and Greek letters to the Latin letters they look like
+var confusables = map[rune]rune{
+	'а': 'a', 'в': 'b', 'с': 'c', 'ԁ': 'd', 'е': 'e', 'һ': 'h', 'і': 'i', 'ј': 'j',
+	'к': 'k', 'ӏ': 'l', 'м': 'm', 'н': 'h', 'о': 'o', 'р': 'p', 'ԛ': 'q', 'г': 'r',
+	'ѕ': 's', 'т': 't', 'ц': 'u', 'ѵ': 'v', 'ԝ': 'w', 'х': 'x', 'у': 'y', 'ѡ': 'w',
+	'α': 'a', 'β': 'b', 'ε': 'e', 'η': 'n', 'ι': 'i', 'κ': 'k', 'ν': 'v', 'ο': 'o',
+	'ρ': 'p', 'τ': 't', 'υ': 'u', 'χ': 'x', 'γ': 'y', 'ω': 'w',
+}
+
+// host is a hostname in its ASCII (punycode) and Unicode forms, plus its
+// skeleton, the Unicode form with look-alike letters replaced by Latin ones
+type host struct {
+	ascii    string
+	unicode  string
+	skeleton string
+}
+
+// normalizeHost lowercases a hostname and converts it to its ASCII and Unicode
+// forms, so that blocklists match however the name was written
+func normalizeHost(name string) (host, error) {
+	name = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+	if name == "" {
+		return host{}, fmt.Errorf("missing host")
+	}
+
+	if ip := parseIP(name); ip != nil {
+		return host{ascii: ip.String(), unicode: ip.String(), skeleton: ip.String()}, nil
+	}
+
+	ascii, err := idna.Lookup.ToASCII(name)
+	if err != nil {
+		return host{}, fmt.Errorf("invalid host %q: %w", name, err)
+	}
+	unicodeName, err := idna.Lookup.ToUnicode(ascii)
+	if err != nil {
+		return host{}, fmt.Errorf("invalid host %q: %w", name, err)
+	}
+
+	skeleton := strings.Map(func(r rune) rune {
+		if latin, ok := confusables[r]; ok {
+			return latin
+		}
+		return r
+	}, unicodeName)
+
+	return host{ascii: ascii, unicode: unicodeName, skeleton: skeleton}, nil
+}
+
+// isPrivateHost reports whether a normalized host is a local name or an
+// address that is not publicly routable
+func isPrivateHost(name string) bool {
+	if name == "localhost" {
+		return true
+	}
+	for _, suffix := range privateSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+
+	ip := net.ParseIP(name)
+	if ip == nil {
+		return false
+	}
+
+	return IsPrivateIP(ip)
+}
+
+// IsPrivateIP reports whether an address is not publicly routable. NAT64
+// addresses are judged by the IPv4 address they translate to
+func IsPrivateIP(ip net.IP) bool {
+	if ip.IsPrivate() || ip.IsLoopback() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() {
+		return true
+	}
+
+	for _, network := range reservedNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	if ip.To4() == nil && nat64Prefix.Contains(ip) {
+		return IsPrivateIP(net.IP(ip.To16()[12:16]))
+	}
+
+	return false
+}
+
+// mustParseCIDR parses a CIDR network, panicking on malformed input
+func mustParseCIDR(cidr string) *net.IPNet {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+
+	return network
+}
+
+// homographLabel returns the first label of a Unicode hostname that mixes
+// Latin with Cyrillic or Greek letters, or is written entirely with letters
+// imitating Latin ones
+func homographLabel(name string) (string, bool) {
+	for _, label := range strings.Split(name, ".") {
+		var latin, lookalike, other, confusable int
+		for _, r := range label {
+			switch {
+			case !unicode.IsLetter(r):
+				continue
+			case unicode.Is(unicode.Latin, r):
+				latin++
+			case unicode.Is(unicode.Cyrillic, r), unicode.Is(unicode.Greek, r):
+				lookalike++
+				if _, ok := confusables[r]; ok {
+					confusable++
+				}
+			default:
+				other++
+			}
+		}
+
+		if latin > 0 && lookalike > 0 {
+			return label, true
+		}
+		if lookalike > 0 && confusable == lookalike && other == 0 {
+			return label, true
+		}
+	}
+
+	return "", false
+}
+
+// parseIP parses an IP address, including the shorthand, octal and hexadecimal
+// IPv4 forms such as 0x7f.1 or 2130706433 that browsers and resolvers accept
+func parseIP(name string) net.IP {
+	if ip := net.ParseIP(strings.Trim(name, "[]")); ip != nil {
+		return ip
+	}
+
+	parts := strings.Split(name, ".")
+	if len(parts) > 4 {
+		return nil
+	}
+
+	values := make([]uint64, len(parts))
+	for i, part := range parts {
+		value, err := strconv.ParseUint(part, 0, 32)
+		if err != nil {
+			return nil
+		}
+		values[i] = value
+	}
+
+	// every part but the last is one byte, the last fills the remaining bytes
+	var addr uint64
+	for i, value := range values[:len(values)-1] {
+		if value > 0xff {
+			return nil
+		}
+		addr |= value << (8 * (3 - i))
+	}
+	last := values[len(values)-1]
+	if last >= 1<<(8*(5-len(values))) {
+		return nil
+	}
+	addr |= last
+
+	return net.IPv4(byte(addr>>24), byte(addr>>16), byte(addr>>8), byte(addr))
+}
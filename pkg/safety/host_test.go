@@ -0,0 +1,48 @@
+package safety
+
+import (
+	"net"
+	"testing"
+)
+
+func TestIsPrivateIP(t *testing.T) {
+	tests := []struct {
+		ip      string
+		private bool
+	}{
+		{"10.1.2.3", true},
+		{"127.0.0.1", true},
+		{"169.254.169.254", true},
+		{"0.0.0.0", true},
+		{"0.1.2.3", true},
+		{"100.64.0.1", true},
+		{"192.0.0.170", true},
+		{"198.18.0.1", true},
+		{"198.19.255.254", true},
+		{"240.0.0.1", true},
+		{"255.255.255.255", true},
+		{"::1", true},
+		{"fd00::1", true},
+		{"fe80::1", true},
+		{"::ffff:127.0.0.1", true},
+		{"64:ff9b::7f00:1", true},
+		{"64:ff9b::a9fe:a9fe", true},
+		{"64:ff9b::c0a8:101", true},
+		{"8.8.8.8", false},
+		{"1.1.1.1", false},
+		{"198.20.0.1", false},
+		{"100.128.0.1", false},
+		{"2606:4700:4700::1111", false},
+		{"64:ff9b::808:808", false},
+	}
+
+	for _, tt := range tests {
+		ip := net.ParseIP(tt.ip)
+		if ip == nil {
+			t.Fatalf("invalid test address %s", tt.ip)
+		}
+		if got := IsPrivateIP(ip); got != tt.private {
+			t.Errorf("IsPrivateIP(%s) = %v, want %v", tt.ip, got, tt.private)
+		}
+	}
+}
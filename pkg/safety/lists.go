@@ -0,0 +1,141 @@
+package safety
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Reload reads the blocklist and pattern files again. On error the lists
+// loaded previously are kept
+func (c *Checker) Reload() error {
+	domains := map[string]bool{}
+	lines, err := readList(c.blocklistFile)
+	if err != nil {
+		return err
+	}
+	for _, line := range lines {
+		host, err := normalizeHost(strings.TrimPrefix(line, "*."))
+		if err != nil {
+			return fmt.Errorf("invalid blocklist entry %q: %w", line, err)
+		}
+		domains[host.ascii] = true
+	}
+
+	var patterns []*regexp.Regexp
+	lines, err = readList(c.patternsFile)
+	if err != nil {
+		return err
+	}
+	for _, line := range lines {
+		pattern, err := regexp.Compile(line)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", line, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+
+	c.mu.Lock()
+	c.domains = domains
+	c.patterns = patterns
+	c.mu.Unlock()
+
+	return nil
+}
+
+// Watch reloads the lists whenever their files change, until Close is called.
+// The directories are watched rather than the files so that editors and config
+// management replacing the files are noticed too
+func (c *Checker) Watch() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to watch safety lists: %w", err)
+	}
+
+	files := map[string]bool{}
+	for _, file := range []string{c.blocklistFile, c.patternsFile} {
+		if file == "" {
+			continue
+		}
+		path, err := filepath.Abs(file)
+		if err != nil {
+			watcher.Close()
+			return err
+		}
+		files[path] = true
+		if err := watcher.Add(filepath.Dir(path)); err != nil {
+			watcher.Close()
+			return fmt.Errorf("failed to watch %s: %w", file, err)
+		}
+	}
+
+	c.watcher = watcher
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if !files[filepath.Clean(event.Name)] || event.Op == fsnotify.Chmod {
+					continue
+				}
+				if err := c.Reload(); err != nil {
+					log.Printf("error reloading safety lists: %v", err)
+				} else {
+					log.Printf("reloaded safety lists after %s", event)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Printf("error watching safety lists: %v", err)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Close stops watching the list files
+func (c *Checker) Close() error {
+	if c.watcher == nil {
+		return nil
+	}
+
+	return c.watcher.Close()
+}
+
+// readList reads the non-empty, non-comment lines of a list file
+func readList(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return lines, nil
+}
@@ -0,0 +1,22 @@
+package safety
+
+import "context"
+
+// ReasonReputation is the reason of URLs blocked by a reputation provider
+const ReasonReputation = "reputation"
+
+// Verdict is the reputation of a URL, Threat naming the threat type of unsafe URLs
+type Verdict struct {
+	Threat string `json:"threat,omitempty"`
+}
+
+// Unsafe reports whether the URL is known to be malicious
+func (v Verdict) Unsafe() bool {
+	return v.Threat != ""
+}
+
+// ReputationProvider looks up the reputation of URLs with a threat-intelligence
+// service. Lookup returns a verdict for every URL asked about
+type ReputationProvider interface {
+	Lookup(ctx context.Context, urls []string) (map[string]Verdict, error)
+}
@@ -0,0 +1,128 @@
+package safety
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// safeBrowsingBatchSize is the maximum number of URLs of a single lookup
+const safeBrowsingBatchSize = 500
+
+// safeBrowsingThreatTypes are the threat types URLs are looked up for
+var safeBrowsingThreatTypes = []string{"MALWARE", "SOCIAL_ENGINEERING", "UNWANTED_SOFTWARE", "POTENTIALLY_HARMFUL_APPLICATION"}
+
+// SafeBrowsingClient looks up URLs with the Safe Browsing v4 Lookup API, or any
+// service speaking the same protocol
+type SafeBrowsingClient struct {
+	endpoint string
+	apiKey   string
+	clientID string
+	client   *http.Client
+}
+
+// NewSafeBrowsingClient creates a client of the Safe Browsing API served at endpoint
+func NewSafeBrowsingClient(endpoint, apiKey, clientID string, client *http.Client) *SafeBrowsingClient {
+	return &SafeBrowsingClient{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		apiKey:   apiKey,
+		clientID: clientID,
+		client:   client,
+	}
+}
+
+type safeBrowsingEntry struct {
+	URL string `json:"url"`
+}
+
+type safeBrowsingRequest struct {
+	Client struct {
+		ClientID      string `json:"clientId"`
+		ClientVersion string `json:"clientVersion"`
+	} `json:"client"`
+	ThreatInfo struct {
+		ThreatTypes      []string            `json:"threatTypes"`
+		PlatformTypes    []string            `json:"platformTypes"`
+		ThreatEntryTypes []string            `json:"threatEntryTypes"`
+		ThreatEntries    []safeBrowsingEntry `json:"threatEntries"`
+	} `json:"threatInfo"`
+}
+
+type safeBrowsingResponse struct {
+	Matches []struct {
+		ThreatType string            `json:"threatType"`
+		Threat     safeBrowsingEntry `json:"threat"`
+	} `json:"matches"`
+}
+
+// Lookup returns the verdicts of urls, looking them up in batches
+func (c *SafeBrowsingClient) Lookup(ctx context.Context, urls []string) (map[string]Verdict, error) {
+	verdicts := make(map[string]Verdict, len(urls))
+	for start := 0; start < len(urls); start += safeBrowsingBatchSize {
+		end := start + safeBrowsingBatchSize
+		if end > len(urls) {
+			end = len(urls)
+		}
+
+		if err := c.lookup(ctx, urls[start:end], verdicts); err != nil {
+			return nil, err
+		}
+	}
+
+	return verdicts, nil
+}
+
+// lookup looks up a batch of URLs, adding their verdicts to verdicts
+func (c *SafeBrowsingClient) lookup(ctx context.Context, urls []string, verdicts map[string]Verdict) error {
+	var body safeBrowsingRequest
+	body.Client.ClientID = c.clientID
+	body.Client.ClientVersion = "1.0"
+	body.ThreatInfo.ThreatTypes = safeBrowsingThreatTypes
+	body.ThreatInfo.PlatformTypes = []string{"ANY_PLATFORM"}
+	body.ThreatInfo.ThreatEntryTypes = []string{"URL"}
+	for _, u := range urls {
+		body.ThreatInfo.ThreatEntries = append(body.ThreatInfo.ThreatEntries, safeBrowsingEntry{URL: u})
+		verdicts[u] = Verdict{}
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode safe browsing request: %w", err)
+	}
+
+	endpoint := c.endpoint + "/v4/threatMatches:find?key=" + url.QueryEscape(c.apiKey)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("invalid safe browsing request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("safe browsing lookup failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		return fmt.Errorf("safe browsing lookup failed with status %d", resp.StatusCode)
+	}
+
+	var result safeBrowsingResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 10<<20)).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode safe browsing response: %w", err)
+	}
+
+	for _, match := range result.Matches {
+		if _, ok := verdicts[match.Threat.URL]; ok {
+			verdicts[match.Threat.URL] = Verdict{Threat: match.ThreatType}
+		}
+	}
+
+	return nil
+}
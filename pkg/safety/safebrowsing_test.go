@@ -0,0 +1,143 @@
+package safety
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// safeBrowsingStub serves the Safe Browsing Lookup API, reporting the URLs
+// containing "malware" as malware and keeping the URLs of every request
+type safeBrowsingStub struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests [][]string
+	status   int
+}
+
+func newSafeBrowsingStub(t *testing.T) *safeBrowsingStub {
+	t.Helper()
+
+	stub := &safeBrowsingStub{status: http.StatusOK}
+	stub.Server = httptest.NewServer(http.HandlerFunc(stub.serve))
+	t.Cleanup(stub.Close)
+
+	return stub
+}
+
+func (s *safeBrowsingStub) serve(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.URL.Path != "/v4/threatMatches:find" || r.URL.Query().Get("key") != "test-key" {
+		http.Error(w, "unexpected request", http.StatusBadRequest)
+		return
+	}
+
+	var req safeBrowsingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var urls []string
+	var resp safeBrowsingResponse
+	for _, entry := range req.ThreatInfo.ThreatEntries {
+		urls = append(urls, entry.URL)
+		if strings.Contains(entry.URL, "malware") {
+			resp.Matches = append(resp.Matches, struct {
+				ThreatType string            `json:"threatType"`
+				Threat     safeBrowsingEntry `json:"threat"`
+			}{ThreatType: "MALWARE", Threat: entry})
+		}
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, urls)
+	status := s.status
+	s.mu.Unlock()
+
+	if status != http.StatusOK {
+		w.WriteHeader(status)
+		return
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+// lookups returns the URLs of every request received so far
+func (s *safeBrowsingStub) lookups() [][]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([][]string(nil), s.requests...)
+}
+
+func (s *safeBrowsingStub) failWith(status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.status = status
+}
+
+func TestSafeBrowsingLookup(t *testing.T) {
+	stub := newSafeBrowsingStub(t)
+	client := NewSafeBrowsingClient(stub.URL+"/", "test-key", "tests", stub.Client())
+
+	verdicts, err := client.Lookup(context.Background(), []string{"https://example.com/", "https://malware.test/payload"})
+	if err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+
+	if verdict := verdicts["https://example.com/"]; verdict.Unsafe() {
+		t.Errorf("example.com verdict = %+v, want safe", verdict)
+	}
+	if verdict := verdicts["https://malware.test/payload"]; verdict.Threat != "MALWARE" {
+		t.Errorf("malware.test verdict = %+v, want MALWARE", verdict)
+	}
+}
+
+func TestSafeBrowsingLookupBatches(t *testing.T) {
+	stub := newSafeBrowsingStub(t)
+	client := NewSafeBrowsingClient(stub.URL, "test-key", "tests", stub.Client())
+
+	urls := make([]string, safeBrowsingBatchSize+1)
+	for i := range urls {
+		urls[i] = fmt.Sprintf("https://example.com/%d", i)
+	}
+
+	verdicts, err := client.Lookup(context.Background(), urls)
+	if err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+
+	if len(verdicts) != len(urls) {
+		t.Errorf("Lookup returned %d verdicts, want one per URL (%d)", len(verdicts), len(urls))
+	}
+	lookups := stub.lookups()
+	if len(lookups) != 2 || len(lookups[0]) != safeBrowsingBatchSize || len(lookups[1]) != 1 {
+		t.Errorf("stub received batches of %v URLs, want %d then 1", batchSizes(lookups), safeBrowsingBatchSize)
+	}
+}
+
+func TestSafeBrowsingLookupFailure(t *testing.T) {
+	stub := newSafeBrowsingStub(t)
+	stub.failWith(http.StatusTooManyRequests)
+	client := NewSafeBrowsingClient(stub.URL, "test-key", "tests", stub.Client())
+
+	if _, err := client.Lookup(context.Background(), []string{"https://example.com/"}); err == nil {
+		t.Fatal("Lookup succeeded on a failing service")
+	}
+}
+
+// batchSizes returns the number of URLs of every lookup
+func batchSizes(lookups [][]string) []int {
+	sizes := make([]int, len(lookups))
+	for i, urls := range lookups {
+		sizes[i] = len(urls)
+	}
+
+	return sizes
+}
@@ -0,0 +1,72 @@
+package shortener
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CheckCharacter returns the Luhn mod N check character of code over the
+// alphabet, catching any single mistyped character and most swaps of
+// adjacent characters
+func CheckCharacter(code, alphabet string) (byte, error) {
+	n := len(alphabet)
+	sum := 0
+	factor := 2
+	for i := len(code) - 1; i >= 0; i-- {
+		digit := strings.IndexByte(alphabet, code[i])
+		if digit < 0 {
+			return 0, fmt.Errorf("invalid code character %q", code[i])
+		}
+
+		addend := factor * digit
+		sum += addend/n + addend%n
+		factor = 3 - factor
+	}
+
+	return alphabet[(n-sum%n)%n], nil
+}
+
+// ValidCheck reports whether the last character of code is the check
+// character of the characters before it
+func ValidCheck(code, alphabet string) bool {
+	if len(code) < 2 {
+		return false
+	}
+
+	check, err := CheckCharacter(code[:len(code)-1], alphabet)
+	return err == nil && check == code[len(code)-1]
+}
+
+// Corrections returns up to limit codes with a valid check character one
+// typo away from code: a character replaced, left out, added or swapped with
+// the next one. The work grows with the square of the code length, so
+// callers bound it
+func Corrections(code, alphabet string, limit int) []string {
+	var corrections []string
+	seen := map[string]bool{code: true}
+	add := func(candidate string) {
+		if len(corrections) < limit && !seen[candidate] && ValidCheck(candidate, alphabet) {
+			seen[candidate] = true
+			corrections = append(corrections, candidate)
+		}
+	}
+
+	for i := 0; i < len(code); i++ {
+		for j := 0; j < len(alphabet); j++ {
+			add(code[:i] + alphabet[j:j+1] + code[i+1:])
+		}
+	}
+	for i := 0; i+1 < len(code); i++ {
+		add(code[:i] + code[i+1:i+2] + code[i:i+1] + code[i+2:])
+	}
+	for i := 0; i < len(code); i++ {
+		add(code[:i] + code[i+1:])
+	}
+	for i := 0; i <= len(code); i++ {
+		for j := 0; j < len(alphabet); j++ {
+			add(code[:i] + alphabet[j:j+1] + code[i:])
+		}
+	}
+
+	return corrections
+}
@@ -0,0 +1,99 @@
+package shortener
+
+import (
+	"context"
+	"testing"
+)
+
+// withCheck appends the check character of code over alphabet
+func withCheck(t *testing.T, code, alphabet string) string {
+	t.Helper()
+
+	check, err := CheckCharacter(code, alphabet)
+	if err != nil {
+		t.Fatalf("CheckCharacter(%q): %v", code, err)
+	}
+
+	return code + string(check)
+}
+
+func TestCheckCharacterDetectsSubstitutions(t *testing.T) {
+	for _, alphabet := range []string{CharSet, UnambiguousCharSet} {
+		for _, code := range []string{"abc234", "Zk9xQ2", "aaaaaa", "x"} {
+			valid := withCheck(t, code, alphabet)
+			if !ValidCheck(valid, alphabet) {
+				t.Fatalf("ValidCheck(%q) = false", valid)
+			}
+
+			// every character, the check character included
+			for i := 0; i < len(valid); i++ {
+				for j := 0; j < len(alphabet); j++ {
+					if alphabet[j] == valid[i] {
+						continue
+					}
+					typo := valid[:i] + alphabet[j:j+1] + valid[i+1:]
+					if ValidCheck(typo, alphabet) {
+						t.Errorf("ValidCheck(%q) = true, a typo of %q", typo, valid)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestCheckCharacterDetectsTranspositions(t *testing.T) {
+	for _, alphabet := range []string{CharSet, UnambiguousCharSet} {
+		first, last := alphabet[0], alphabet[len(alphabet)-1]
+
+		for i := 0; i < len(alphabet); i++ {
+			for j := 0; j < len(alphabet); j++ {
+				if i == j {
+					continue
+				}
+				// the swapped pair at both parities of the code
+				for _, prefix := range []string{"", "k"} {
+					code := prefix + alphabet[i:i+1] + alphabet[j:j+1] + "m"
+					swapped := prefix + alphabet[j:j+1] + alphabet[i:i+1] + "m"
+					check, err := CheckCharacter(code, alphabet)
+					if err != nil {
+						t.Fatalf("CheckCharacter(%q): %v", code, err)
+					}
+
+					detected := !ValidCheck(swapped+string(check), alphabet)
+					// Luhn mod N cannot tell the first and last characters apart when swapped
+					undetectable := (alphabet[i] == first && alphabet[j] == last) || (alphabet[i] == last && alphabet[j] == first)
+					if detected == undetectable {
+						t.Errorf("swapping %q into %q detected = %v, want %v", code, swapped, detected, !undetectable)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestValidCheckRejects(t *testing.T) {
+	tests := []string{"", "a", "ab-c"}
+
+	for _, code := range tests {
+		if ValidCheck(code, CharSet) {
+			t.Errorf("ValidCheck(%q) = true, want false", code)
+		}
+	}
+}
+
+func TestGeneratedCodesPassValidCheck(t *testing.T) {
+	for _, alphabet := range []string{CharSet, UnambiguousCharSet} {
+		s := NewShortener(6, nil, NewPolicy(alphabet, DefaultDenylist, DefaultReservedWords))
+		s.EnableCheckCharacter()
+
+		for i := 0; i < 1000; i++ {
+			code, err := s.Generate(context.Background())
+			if err != nil {
+				t.Fatalf("Generate: %v", err)
+			}
+			if len(code) != 7 || !ValidCheck(code, alphabet) {
+				t.Fatalf("Generate = %q, want 6 characters and a valid check character", code)
+			}
+		}
+	}
+}
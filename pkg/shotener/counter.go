@@ -0,0 +1,83 @@
+package shortener
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"url_shortener/pkg/cache"
+
+	"gorm.io/gorm"
+)
+
+// SequenceCounter hands out the numbers of a Postgres sequence
+type SequenceCounter struct {
+	db   *gorm.DB
+	name string
+}
+
+// NewSequenceCounter creates a counter backed by the Postgres sequence name,
+// creating the sequence when it does not exist
+func NewSequenceCounter(db *gorm.DB, name string) (*SequenceCounter, error) {
+	if err := db.Exec(fmt.Sprintf("CREATE SEQUENCE IF NOT EXISTS %s MINVALUE 0 START 0", name)).Error; err != nil {
+		return nil, fmt.Errorf("failed to create sequence %s: %w", name, err)
+	}
+
+	return &SequenceCounter{db: db, name: name}, nil
+}
+
+// Next returns the next number of the sequence
+func (c *SequenceCounter) Next(ctx context.Context) (uint64, error) {
+	var n int64
+	if err := c.db.WithContext(ctx).Raw("SELECT nextval(?::regclass)", c.name).Scan(&n).Error; err != nil {
+		return 0, fmt.Errorf("failed to read sequence %s: %w", c.name, err)
+	}
+
+	return uint64(n), nil
+}
+
+// BlockCounter hands out numbers from blocks reserved with a Redis INCRBY, so
+// that only one in blockSize numbers costs a round trip. Numbers of a block
+// left when the process stops are never handed out
+type BlockCounter struct {
+	cache     *cache.RedisClient
+	key       string
+	blockSize int64
+
+	mu   sync.Mutex
+	next uint64
+	end  uint64
+}
+
+// NewBlockCounter creates a counter reserving blocks of blockSize numbers from the Redis key
+func NewBlockCounter(cache *cache.RedisClient, key string, blockSize int64) *BlockCounter {
+	if blockSize <= 0 {
+		blockSize = 1
+	}
+
+	return &BlockCounter{
+		cache:     cache,
+		key:       key,
+		blockSize: blockSize,
+	}
+}
+
+// Next returns the next number of the current block, reserving a new block when it is used up
+func (c *BlockCounter) Next(ctx context.Context) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.next == c.end {
+		end, err := c.cache.IncrementBy(ctx, c.key, c.blockSize)
+		if err != nil {
+			return 0, fmt.Errorf("failed to reserve short code block: %w", err)
+		}
+		c.end = uint64(end)
+		c.next = c.end - uint64(c.blockSize)
+	}
+
+	n := c.next
+	c.next++
+
+	return n, nil
+}
@@ -0,0 +1,53 @@
+package shortener
+
+import (
+	"context"
+	"testing"
+
+	"url_shortener/pkg/cache"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func TestBlockCounterHandsOffBlocks(t *testing.T) {
+	server := miniredis.RunT(t)
+	client, err := cache.NewRedisClient(server.Addr(), "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	// two replicas sharing the key each reserve blocks of 3 numbers
+	a := NewBlockCounter(client, "short_code_counter", 3)
+	b := NewBlockCounter(client, "short_code_counter", 3)
+
+	steps := []struct {
+		counter *BlockCounter
+		want    uint64
+	}{
+		{a, 0},
+		{b, 3},
+		{a, 1},
+		{a, 2},
+		{a, 6},
+		{b, 4},
+		{b, 5},
+		{b, 9},
+		{a, 7},
+	}
+
+	for i, step := range steps {
+		n, err := step.counter.Next(context.Background())
+		if err != nil {
+			t.Fatalf("step %d: Next: %v", i, err)
+		}
+		if n != step.want {
+			t.Errorf("step %d: Next = %d, want %d", i, n, step.want)
+		}
+	}
+
+	// four blocks were reserved, the rest of the last ones left to their replica
+	if reserved, _ := server.Get("short_code_counter"); reserved != "12" {
+		t.Errorf("reserved up to %s, want 12", reserved)
+	}
+}
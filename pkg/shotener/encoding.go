@@ -0,0 +1,63 @@
+package shortener
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Encode writes n in the base of the alphabet, with its characters as digits,
+// left-padded with its first character to at least length characters
+func Encode(n uint64, alphabet string, length int) string {
+	base := uint64(len(alphabet))
+	var buf [64]byte
+	i := len(buf)
+	for {
+		i--
+		buf[i] = alphabet[n%base]
+		n /= base
+		if n == 0 {
+			break
+		}
+	}
+
+	code := string(buf[i:])
+	if len(code) < length {
+		code = strings.Repeat(alphabet[:1], length-len(code)) + code
+	}
+
+	return code
+}
+
+// Decode decodes a code encoded by Encode with the same alphabet
+func Decode(code, alphabet string) (uint64, error) {
+	base := uint64(len(alphabet))
+	var n uint64
+	for _, c := range code {
+		digit := strings.IndexRune(alphabet, c)
+		if digit < 0 {
+			return 0, fmt.Errorf("invalid code character %q", c)
+		}
+		if n > (math.MaxUint64-uint64(digit))/base {
+			return 0, fmt.Errorf("code %q overflows", code)
+		}
+		n = n*base + uint64(digit)
+	}
+
+	return n, nil
+}
+
+// KeyspaceSize returns the number of codes of length characters of the
+// alphabet, capped at the largest uint64
+func KeyspaceSize(alphabet string, length int) uint64 {
+	base := uint64(len(alphabet))
+	size := uint64(1)
+	for i := 0; i < length; i++ {
+		if size > math.MaxUint64/base {
+			return math.MaxUint64
+		}
+		size *= base
+	}
+
+	return size
+}
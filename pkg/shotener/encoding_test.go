@@ -0,0 +1,77 @@
+package shortener
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	tests := []struct {
+		n        uint64
+		alphabet string
+		length   int
+		want     string
+	}{
+		{0, "01", 0, "0"},
+		{0, "01", 4, "0000"},
+		{5, "01", 4, "0101"},
+		{15, "01", 4, "1111"},
+		{16, "01", 4, "10000"},
+		{0, CharSet, 6, "aaaaaa"},
+		{61, CharSet, 1, "9"},
+		{62, CharSet, 1, "ba"},
+		{KeyspaceSize(CharSet, 6) - 1, CharSet, 6, "999999"},
+		{KeyspaceSize(CharSet, 6), CharSet, 6, "baaaaaa"},
+		{math.MaxUint64, "0123456789", 0, "18446744073709551615"},
+	}
+
+	for _, tt := range tests {
+		code := Encode(tt.n, tt.alphabet, tt.length)
+		if code != tt.want {
+			t.Errorf("Encode(%d, %q, %d) = %q, want %q", tt.n, tt.alphabet, tt.length, code, tt.want)
+			continue
+		}
+
+		n, err := Decode(code, tt.alphabet)
+		if err != nil || n != tt.n {
+			t.Errorf("Decode(%q) = %d, %v, want %d", code, n, err, tt.n)
+		}
+	}
+}
+
+func TestDecodeRejects(t *testing.T) {
+	tests := []struct {
+		code     string
+		alphabet string
+	}{
+		{"ab-c", CharSet},
+		{"18446744073709551616", "0123456789"},
+		{"99999999999999999999", "0123456789"},
+	}
+
+	for _, tt := range tests {
+		if n, err := Decode(tt.code, tt.alphabet); err == nil {
+			t.Errorf("Decode(%q) = %d, want an error", tt.code, n)
+		}
+	}
+}
+
+func TestKeyspaceSize(t *testing.T) {
+	tests := []struct {
+		alphabet string
+		length   int
+		want     uint64
+	}{
+		{CharSet, 0, 1},
+		{CharSet, 1, 62},
+		{CharSet, 6, 56800235584},
+		{"01", 64, math.MaxUint64},
+		{CharSet, 20, math.MaxUint64},
+	}
+
+	for _, tt := range tests {
+		if got := KeyspaceSize(tt.alphabet, tt.length); got != tt.want {
+			t.Errorf("KeyspaceSize(%d characters, %d) = %d, want %d", len(tt.alphabet), tt.length, got, tt.want)
+		}
+	}
+}
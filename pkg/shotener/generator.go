@@ -0,0 +1,96 @@
+package shortener
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+// ErrKeyspaceExhausted is returned when every code of the configured length was handed out
+var ErrKeyspaceExhausted = errors.New("short code keyspace exhausted")
+
+// CodeGenerator produces short codes for new links
+type CodeGenerator interface {
+	Generate(ctx context.Context) (string, error)
+}
+
+// Resizable is implemented by the generators whose code length can change
+// while they are in use
+type Resizable interface {
+	Length() int
+	SetLength(length int)
+}
+
+// RandomGenerator picks codes of random characters, relying on the caller to
+// retry on collisions
+type RandomGenerator struct {
+	alphabet string
+	length   atomic.Int64
+}
+
+// NewRandomGenerator creates a generator of random codes of length
+// characters of alphabet
+func NewRandomGenerator(alphabet string, length int) *RandomGenerator {
+	g := &RandomGenerator{alphabet: alphabet}
+	g.length.Store(int64(length))
+	return g
+}
+
+// Generate returns a random code
+func (g *RandomGenerator) Generate(ctx context.Context) (string, error) {
+	return generateRandomString(g.alphabet, g.Length())
+}
+
+// Length returns the length of the generated codes
+func (g *RandomGenerator) Length() int {
+	return int(g.length.Load())
+}
+
+// SetLength changes the length of the codes generated from now on
+func (g *RandomGenerator) SetLength(length int) {
+	g.length.Store(int64(length))
+}
+
+// Counter hands out globally unique, increasing numbers
+type Counter interface {
+	Next(ctx context.Context) (uint64, error)
+}
+
+// SequenceGenerator encodes the numbers of a counter with an alphabet, so codes never
+// collide with each other. An optional permutation scrambles the numbers so
+// that consecutive links do not get guessable codes
+type SequenceGenerator struct {
+	counter     Counter
+	permutation *Permutation
+	alphabet    string
+	length      int
+}
+
+// NewSequenceGenerator creates a generator of codes of at least length
+// characters of alphabet from counter, scrambled by permutation unless it is
+// nil. The permutation must cover the codes of length characters
+func NewSequenceGenerator(counter Counter, permutation *Permutation, alphabet string, length int) *SequenceGenerator {
+	return &SequenceGenerator{
+		counter:     counter,
+		permutation: permutation,
+		alphabet:    alphabet,
+		length:      length,
+	}
+}
+
+// Generate returns the code of the next number of the counter
+func (g *SequenceGenerator) Generate(ctx context.Context) (string, error) {
+	n, err := g.counter.Next(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if g.permutation != nil {
+		if n >= g.permutation.Size() {
+			return "", ErrKeyspaceExhausted
+		}
+		n = g.permutation.Apply(n)
+	}
+
+	return Encode(n, g.alphabet, g.length), nil
+}
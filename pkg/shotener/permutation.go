@@ -0,0 +1,96 @@
+package shortener
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"math/bits"
+)
+
+// feistelRounds is the number of rounds of the Feistel network
+const feistelRounds = 4
+
+// Permutation is a keyed, reversible shuffle of the numbers below a size,
+// built from a Feistel network with cycle walking. It maps a sequence to
+// codes that look random while staying unique
+type Permutation struct {
+	key      []byte
+	size     uint64
+	halfBits uint
+	halfMask uint64
+}
+
+// NewPermutation creates the permutation of the numbers below size keyed by key
+func NewPermutation(key []byte, size uint64) *Permutation {
+	// the network works on an even number of bits covering size
+	width := uint(bits.Len64(size - 1))
+	if width%2 == 1 {
+		width++
+	}
+	if width < 2 {
+		width = 2
+	}
+
+	return &Permutation{
+		key:      key,
+		size:     size,
+		halfBits: width / 2,
+		halfMask: 1<<(width/2) - 1,
+	}
+}
+
+// Size returns the number of values the permutation shuffles
+func (p *Permutation) Size() uint64 {
+	return p.size
+}
+
+// Apply returns the image of n, which must be below Size
+func (p *Permutation) Apply(n uint64) uint64 {
+	// the network may leave the domain, walking the cycle until back in it
+	for {
+		n = p.encrypt(n)
+		if n < p.size {
+			return n
+		}
+	}
+}
+
+// Invert returns the number whose image is n
+func (p *Permutation) Invert(n uint64) uint64 {
+	for {
+		n = p.decrypt(n)
+		if n < p.size {
+			return n
+		}
+	}
+}
+
+func (p *Permutation) encrypt(n uint64) uint64 {
+	left, right := n>>p.halfBits, n&p.halfMask
+	for round := 0; round < feistelRounds; round++ {
+		left, right = right, left^p.round(round, right)
+	}
+
+	return left<<p.halfBits | right
+}
+
+func (p *Permutation) decrypt(n uint64) uint64 {
+	left, right := n>>p.halfBits, n&p.halfMask
+	for round := feistelRounds - 1; round >= 0; round-- {
+		left, right = right^p.round(round, left), left
+	}
+
+	return left<<p.halfBits | right
+}
+
+// round is the keyed round function of the network
+func (p *Permutation) round(round int, half uint64) uint64 {
+	var input [9]byte
+	input[0] = byte(round)
+	binary.BigEndian.PutUint64(input[1:], half)
+
+	mac := hmac.New(sha256.New, p.key)
+	mac.Write(input[:])
+
+	return binary.BigEndian.Uint64(mac.Sum(nil)) & p.halfMask
+}
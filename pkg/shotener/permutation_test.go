@@ -0,0 +1,52 @@
+package shortener
+
+import "testing"
+
+func TestPermutationIsBijective(t *testing.T) {
+	tests := []struct {
+		key  string
+		size uint64
+	}{
+		{"key", 1},
+		{"key", 2},
+		{"key", 7},
+		{"key", 64},
+		{"key", 1000},
+		{"other-key", 1000},
+		{"", 3844},
+	}
+
+	for _, tt := range tests {
+		p := NewPermutation([]byte(tt.key), tt.size)
+		seen := make(map[uint64]bool, tt.size)
+		for n := uint64(0); n < tt.size; n++ {
+			image := p.Apply(n)
+			if image >= tt.size {
+				t.Fatalf("size %d: Apply(%d) = %d, out of the domain", tt.size, n, image)
+			}
+			if seen[image] {
+				t.Fatalf("size %d: Apply(%d) = %d, already the image of another number", tt.size, n, image)
+			}
+			seen[image] = true
+
+			if back := p.Invert(image); back != n {
+				t.Fatalf("size %d: Invert(Apply(%d)) = %d", tt.size, n, back)
+			}
+		}
+	}
+}
+
+func TestPermutationDependsOnKey(t *testing.T) {
+	a := NewPermutation([]byte("key"), 1000)
+	b := NewPermutation([]byte("other-key"), 1000)
+
+	same := 0
+	for n := uint64(0); n < 1000; n++ {
+		if a.Apply(n) == b.Apply(n) {
+			same++
+		}
+	}
+	if same > 50 {
+		t.Errorf("%d of 1000 numbers have the same image under both keys", same)
+	}
+}
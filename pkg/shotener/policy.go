@@ -0,0 +1,128 @@
+package shortener
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// UnambiguousCharSet is CharSet without the characters people confuse when
+// reading printed links: 0, O and o, and 1, l and I
+const UnambiguousCharSet = "abcdefghijkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+var (
+	// ErrInvalidCode is returned for codes of the wrong length or characters
+	ErrInvalidCode = errors.New("code must be 3 to 20 letters, digits, dashes or underscores")
+	// ErrReservedCode is returned for codes clashing with a route or reserved word
+	ErrReservedCode = errors.New("code is reserved")
+	// ErrOffensiveCode is returned for codes containing a denied word
+	ErrOffensiveCode = errors.New("code contains a denied word")
+)
+
+// DefaultReservedWords are the codes taken by the routes of the service
+var DefaultReservedWords = []string{
+	"api", "health", "metrics", "swagger", "report", "docs",
+	"admin", "auth", "login", "logout", "signup", "register",
+	"static", "assets", "favicon", "robots", "sitemap", "www",
+}
+
+// DefaultDenylist are the words codes must not contain when no denylist file
+// is configured. Words that often appear inside harmless ones, such as arse in
+// parser or cock in peacock, are left out to keep custom codes usable
+var DefaultDenylist = []string{
+	"bitch", "bollock", "boob", "cunt", "dildo",
+	"fag", "fuck", "jizz", "nazi", "nigg", "penis", "piss", "porn",
+	"pussy", "shit", "slut", "twat", "vagina", "wank", "whore",
+}
+
+// leet folds leetspeak digits and look-alike letters together and drops
+// separators, so that "sh1t", "5hit" and "s-hit" match "shit"
+var leet = strings.NewReplacer(
+	"0", "o", "1", "i", "l", "i", "3", "e", "4", "a",
+	"5", "s", "7", "t", "8", "b", "9", "g", "-", "", "_", "",
+)
+
+// Policy restricts the codes handed out: the alphabet generated codes are
+// made of, and the words no code may be or contain
+type Policy struct {
+	alphabet string
+	denylist []string
+	reserved map[string]bool
+}
+
+// NewPolicy creates a policy generating codes from alphabet, CharSet when
+// empty, and rejecting the codes containing a word of denylist or equal to a
+// reserved word, whatever their case
+func NewPolicy(alphabet string, denylist, reserved []string) *Policy {
+	if alphabet == "" {
+		alphabet = CharSet
+	}
+
+	p := &Policy{
+		alphabet: alphabet,
+		reserved: make(map[string]bool, len(reserved)),
+	}
+	for _, word := range denylist {
+		if word = fold(word); word != "" {
+			p.denylist = append(p.denylist, word)
+		}
+	}
+	for _, word := range reserved {
+		p.reserved[strings.ToLower(word)] = true
+	}
+
+	return p
+}
+
+// Alphabet returns the characters generated codes are made of
+func (p *Policy) Alphabet() string {
+	return p.alphabet
+}
+
+// Check returns ErrReservedCode or ErrOffensiveCode when the policy rejects code
+func (p *Policy) Check(code string) error {
+	if p.reserved[strings.ToLower(code)] {
+		return ErrReservedCode
+	}
+
+	folded := fold(code)
+	for _, word := range p.denylist {
+		if strings.Contains(folded, word) {
+			return ErrOffensiveCode
+		}
+	}
+
+	return nil
+}
+
+// fold lowercases a word and undoes leetspeak
+func fold(word string) string {
+	return leet.Replace(strings.ToLower(strings.TrimSpace(word)))
+}
+
+// LoadWordList reads a file of one word per line, skipping blank lines and
+// # comments
+func LoadWordList(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return words, nil
+}
@@ -0,0 +1,32 @@
+package shortener
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPolicyCheck(t *testing.T) {
+	policy := NewPolicy("", DefaultDenylist, DefaultReservedWords)
+
+	tests := []struct {
+		code string
+		want error
+	}{
+		{"parser", nil},
+		{"sparse", nil},
+		{"peacock", nil},
+		{"dickens", nil},
+		{"F-U-C-K", ErrOffensiveCode},
+		{"sh1t", ErrOffensiveCode},
+		{"5-hit", ErrOffensiveCode},
+		{"API", ErrReservedCode},
+		{"api-docs", nil},
+		{"spring-sale", nil},
+	}
+
+	for _, tt := range tests {
+		if err := policy.Check(tt.code); !errors.Is(err, tt.want) {
+			t.Errorf("Check(%q) = %v, want %v", tt.code, err, tt.want)
+		}
+	}
+}
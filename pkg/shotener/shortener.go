@@ -1,7 +1,9 @@
 package shortener
 
 import (
+	"context"
 	"crypto/rand"
+	"fmt"
 	"math/big"
 	"strings"
 )
@@ -12,52 +14,161 @@ const (
 
 	// CharSet defines the characters to be used in shortcodes
 	CharSet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+	// Separators are the characters custom codes may also contain, between
+	// other characters
+	Separators = "-_"
+
+	// maxPolicyAttempts is the number of generated codes tried before giving
+	// up on finding one the policy allows
+	maxPolicyAttempts = 10
+
+	// maxCorrections is the number of corrections returned for a mistyped code
+	maxCorrections = 32
 )
 
 // Shortener handles the URL shortening logic
 type Shortener struct {
-	length int
+	length         int
+	generator      CodeGenerator
+	policy         *Policy
+	checkCharacter bool
 }
 
-// new Shortener creates new url
-func NewShortener(length int) *Shortener {
+// new Shortener creates new url, generating codes with generator or random
+// codes of length characters when it is nil. Codes are checked against
+// policy, which defaults to unrestricted base62 codes
+func NewShortener(length int, generator CodeGenerator, policy *Policy) *Shortener {
 	if length <= 0 {
 		length = DefaultLength
 	}
+	if policy == nil {
+		policy = NewPolicy(CharSet, nil, nil)
+	}
+	if generator == nil {
+		generator = NewRandomGenerator(policy.Alphabet(), length)
+	}
 
-	return &Shortener{length: length}
+	return &Shortener{length: length, generator: generator, policy: policy}
 }
 
-// generate new unique short code
-func (s *Shortener) Generate() (string, error) {
-	return generateRandomString(s.length)
+// EnableCheckCharacter appends a check character to the generated codes, so
+// that mistyped codes can be told apart and corrected
+func (s *Shortener) EnableCheckCharacter() {
+	s.checkCharacter = true
 }
 
-// checks if a custom short code is valid
-func (s *Shortener) IsValidCustomCode(code string) bool {
-	if len(code) < 3 || len(code) > 20 {
-		return false
+// generate new unique short code, skipping the codes the policy rejects
+func (s *Shortener) Generate(ctx context.Context) (string, error) {
+	for i := 0; i < maxPolicyAttempts; i++ {
+		code, err := s.generator.Generate(ctx)
+		if err != nil {
+			return "", err
+		}
+		if s.checkCharacter {
+			check, err := CheckCharacter(code, s.policy.Alphabet())
+			if err != nil {
+				return "", err
+			}
+			code += string(check)
+		}
+		if s.policy.Check(code) == nil {
+			return code, nil
+		}
+	}
+
+	return "", fmt.Errorf("no code allowed by the policy after %d attempts", maxPolicyAttempts)
+}
+
+// Alphabet returns the characters generated codes are made of
+func (s *Shortener) Alphabet() string {
+	return s.policy.Alphabet()
+}
+
+// Length returns the length of the generated codes
+func (s *Shortener) Length() int {
+	if g, ok := s.generator.(Resizable); ok {
+		return g.Length()
+	}
+	return s.length
+}
+
+// SetLength changes the length of the codes generated from now on, and
+// reports whether the generator supports it
+func (s *Shortener) SetLength(length int) bool {
+	g, ok := s.generator.(Resizable)
+	if ok {
+		g.SetLength(length)
+	}
+	return ok
+}
+
+// KeyspaceSize returns the number of codes of length characters the
+// shortener can generate
+func (s *Shortener) KeyspaceSize(length int) uint64 {
+	if s.checkCharacter {
+		length--
+	}
+	return KeyspaceSize(s.policy.Alphabet(), length)
+}
+
+// Corrections returns the codes a mistyped generated code may have been
+// meant as, none when check characters are disabled, the code is valid or it
+// cannot be a generated code with a typo: longer than a generated code with
+// one extra character, or made of characters outside the alphabet
+func (s *Shortener) Corrections(code string) []string {
+	alphabet := s.policy.Alphabet()
+	if !s.checkCharacter || ValidCheck(code, alphabet) {
+		return nil
+	}
+
+	// generated codes are Length()+1 characters long with their check character
+	if len(code) > min(s.Length()+2, MaxCodeLength) {
+		return nil
+	}
+	for i := 0; i < len(code); i++ {
+		if strings.IndexByte(alphabet, code[i]) < 0 {
+			return nil
+		}
+	}
+
+	return Corrections(code, alphabet, maxCorrections)
+}
+
+// checks a custom short code, returning why it cannot be used. Custom codes
+// may separate words with dashes and underscores
+func (s *Shortener) ValidateCustomCode(code string) error {
+	if len(code) < 3 || len(code) > MaxCodeLength {
+		return ErrInvalidCode
 	}
 
-	for _, c := range code {
-		if !strings.ContainsRune(CharSet, c) {
-			return false
+	for i, c := range code {
+		if strings.ContainsRune(CharSet, c) {
+			continue
+		}
+		if !strings.ContainsRune(Separators, c) || i == 0 || i == len(code)-1 {
+			return ErrInvalidCode
 		}
 	}
 
-	return true
+	return s.policy.Check(code)
+}
+
+// checks if a custom short code is valid
+func (s *Shortener) IsValidCustomCode(code string) bool {
+	return s.ValidateCustomCode(code) == nil
 }
 
-func generateRandomString(length int) (string, error) {
+func generateRandomString(alphabet string, length int) (string, error) {
 	result := make([]byte, length)
-	charsetLength := big.NewInt(int64(len(CharSet)))
+	charsetLength := big.NewInt(int64(len(alphabet)))
 
 	for i := 0; i < length; i++ {
 		randomIndex, err := rand.Int(rand.Reader, charsetLength)
 		if err != nil {
 			return "", err
 		}
-		result[i] = CharSet[randomIndex.Int64()]
+		result[i] = alphabet[randomIndex.Int64()]
 	}
 
 	return string(result), nil
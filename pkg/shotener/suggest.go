@@ -0,0 +1,51 @@
+package shortener
+
+import (
+	"strconv"
+	"time"
+)
+
+// MaxCodeLength is the longest custom code accepted
+const MaxCodeLength = 20
+
+var (
+	// suggestionPrefixes and suggestionSuffixes are the words put around a
+	// taken vanity code to suggest other ones
+	suggestionPrefixes = []string{"get", "go", "try", "my"}
+	suggestionSuffixes = []string{"now", "hq", "app", "link"}
+	// joiners put a vanity code and the words or numbers around it together
+	joiners = []string{"", "-", "_"}
+)
+
+// Variants returns the codes to suggest in place of the vanity code base,
+// taking turns between the ones with the year, with a word around it and
+// with a number. The variants may be taken or rejected by a policy
+func Variants(base string, now time.Time) []string {
+	year := strconv.Itoa(now.Year())
+
+	var years, words, numbers []string
+	for _, sep := range joiners {
+		years = append(years, base+sep+year)
+		for _, suffix := range suggestionSuffixes {
+			words = append(words, base+sep+suffix)
+		}
+		for _, prefix := range suggestionPrefixes {
+			words = append(words, prefix+sep+base)
+		}
+	}
+	years = append(years, base+year[2:])
+	for n := 2; n <= 9; n++ {
+		numbers = append(numbers, base+strconv.Itoa(n), base+"-"+strconv.Itoa(n))
+	}
+
+	var variants []string
+	for i := 0; i < max(len(years), len(words), len(numbers)); i++ {
+		for _, group := range [][]string{years, words, numbers} {
+			if i < len(group) && len(group[i]) <= MaxCodeLength {
+				variants = append(variants, group[i])
+			}
+		}
+	}
+
+	return variants
+}
@@ -0,0 +1,115 @@
+package unshorten
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DefaultHosts are well-known third-party URL shorteners
+var DefaultHosts = []string{
+	"bit.ly", "bitly.com", "tinyurl.com", "t.co", "goo.gl", "ow.ly", "is.gd", "buff.ly",
+	"rebrand.ly", "cutt.ly", "shorturl.at", "rb.gy", "tiny.cc", "t.ly", "s.id", "v.gd", "lnkd.in",
+}
+
+// ErrTooManyHops is returned when a chain of shorteners is longer than the hop limit
+var ErrTooManyHops = errors.New("too many shortener hops")
+
+// Expander follows the redirects of known URL shorteners to the URL they
+// hide. Only shortener hosts are ever requested, the final target is not
+type Expander struct {
+	hosts   map[string]bool
+	client  *http.Client
+	maxHops int
+}
+
+// NewExpander creates an expander of the URLs of hosts, following at most
+// maxHops shortener redirects. Redirects are never followed by client itself
+func NewExpander(hosts []string, client *http.Client, maxHops int) *Expander {
+	set := make(map[string]bool, len(hosts))
+	for _, host := range hosts {
+		if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+			set[host] = true
+		}
+	}
+
+	c := *client
+	c.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	return &Expander{
+		hosts:   set,
+		client:  &c,
+		maxHops: maxHops,
+	}
+}
+
+// IsShortener reports whether a URL is on a known shortener host
+func (e *Expander) IsShortener(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+
+	return e.hosts[strings.TrimPrefix(strings.ToLower(u.Host), "www.")]
+}
+
+// Expand returns the first URL of the redirect chain of rawURL that is not on a
+// known shortener, rawURL itself when it is not a shortener URL
+func (e *Expander) Expand(ctx context.Context, rawURL string) (string, error) {
+	current := rawURL
+	for hops := 0; e.IsShortener(current); hops++ {
+		if hops == e.maxHops {
+			return "", fmt.Errorf("%w: %s", ErrTooManyHops, rawURL)
+		}
+
+		next, err := e.resolve(ctx, current)
+		if err != nil {
+			return "", err
+		}
+		current = next
+	}
+
+	return current, nil
+}
+
+// resolve returns the redirect target of a shortener URL
+func (e *Expander) resolve(ctx context.Context, rawURL string) (string, error) {
+	resp, err := e.request(ctx, http.MethodHead, rawURL)
+	if err == nil && resp.StatusCode == http.StatusMethodNotAllowed {
+		resp.Body.Close()
+		resp, err = e.request(ctx, http.MethodGet, rawURL)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to expand %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 300 || resp.StatusCode >= 400 {
+		return "", fmt.Errorf("failed to expand %s: shortener responded with status %d", rawURL, resp.StatusCode)
+	}
+
+	location, err := resp.Location()
+	if err != nil {
+		return "", fmt.Errorf("failed to expand %s: %w", rawURL, err)
+	}
+
+	return location.String(), nil
+}
+
+// request sends a request to a shortener without following its redirect
+func (e *Expander) request(ctx context.Context, method, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "url-shortener-expander/1.0")
+
+	return e.client.Do(req)
+}
@@ -0,0 +1,111 @@
+package unshorten
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// newShortener starts a shortener redirecting every request to target
+func newShortener(t *testing.T, target func() string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target(), http.StatusMovedPermanently)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// hostOf returns the host of a test server URL
+func hostOf(t *testing.T, rawURL string) string {
+	t.Helper()
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return u.Host
+}
+
+func TestExpandFollowsShortenerChain(t *testing.T) {
+	last := newShortener(t, func() string { return "https://example.com/page" })
+	first := newShortener(t, func() string { return last.URL + "/b" })
+
+	expander := NewExpander([]string{hostOf(t, first.URL), hostOf(t, last.URL)}, http.DefaultClient, 5)
+
+	got, err := expander.Expand(context.Background(), first.URL+"/a")
+	if err != nil {
+		t.Fatalf("Expand: %v", err)
+	}
+	if got != "https://example.com/page" {
+		t.Errorf("Expand = %q, want %q", got, "https://example.com/page")
+	}
+}
+
+func TestExpandStopsAtHopLimit(t *testing.T) {
+	var loop *httptest.Server
+	loop = newShortener(t, func() string { return loop.URL + "/again" })
+
+	expander := NewExpander([]string{hostOf(t, loop.URL)}, http.DefaultClient, 3)
+
+	_, err := expander.Expand(context.Background(), loop.URL+"/start")
+	if !errors.Is(err, ErrTooManyHops) {
+		t.Fatalf("Expand error = %v, want ErrTooManyHops", err)
+	}
+}
+
+func TestExpandFallsBackToGet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		http.Redirect(w, r, "https://example.com/get", http.StatusFound)
+	}))
+	defer server.Close()
+
+	expander := NewExpander([]string{hostOf(t, server.URL)}, http.DefaultClient, 5)
+
+	got, err := expander.Expand(context.Background(), server.URL+"/x")
+	if err != nil {
+		t.Fatalf("Expand: %v", err)
+	}
+	if got != "https://example.com/get" {
+		t.Errorf("Expand = %q, want %q", got, "https://example.com/get")
+	}
+}
+
+func TestExpandReportsShortenerFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	expander := NewExpander([]string{hostOf(t, server.URL)}, http.DefaultClient, 5)
+
+	_, err := expander.Expand(context.Background(), server.URL+"/x")
+	if err == nil {
+		t.Fatal("Expand succeeded on a failing shortener")
+	}
+	if errors.Is(err, ErrTooManyHops) {
+		t.Errorf("Expand error = %v, want a failure other than ErrTooManyHops", err)
+	}
+}
+
+func TestExpandLeavesOtherURLs(t *testing.T) {
+	expander := NewExpander(DefaultHosts, http.DefaultClient, 5)
+
+	got, err := expander.Expand(context.Background(), "https://example.com/page")
+	if err != nil {
+		t.Fatalf("Expand: %v", err)
+	}
+	if got != "https://example.com/page" {
+		t.Errorf("Expand = %q, want the URL unchanged", got)
+	}
+}
@@ -0,0 +1,34 @@
+package urlnorm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/PuerkitoBio/purell"
+)
+
+// flags are the normalizations that keep a URL pointing to the same page:
+// lowercasing the scheme and host, dropping default ports, empty query and
+// port separators and the trailing slash, resolving dot segments, decoding
+// unneeded escapes and sorting the query
+const flags = purell.FlagsUsuallySafeGreedy |
+	purell.FlagSortQuery |
+	purell.FlagRemoveUnnecessaryHostDots |
+	purell.FlagRemoveEmptyPortSeparator
+
+// Normalize returns the canonical form of a URL, equal for URLs which only
+// differ in the way they are written
+func Normalize(rawURL string) (string, error) {
+	return purell.NormalizeURLString(rawURL, flags)
+}
+
+// Hash returns the hex SHA-256 of the canonical form of a URL
+func Hash(rawURL string) (string, error) {
+	normalized, err := Normalize(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,75 @@
+package urlnorm
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		// scheme and host case, but never the path or query case
+		{"HTTPS://Example.COM/Path", "https://example.com/Path"},
+		{"https://example.com/a?B=1", "https://example.com/a?B=1"},
+		// default ports and empty port separators, other ports kept
+		{"http://example.com:80/", "http://example.com"},
+		{"https://example.com:443/a", "https://example.com/a"},
+		{"https://example.com:/a", "https://example.com/a"},
+		{"http://example.com:8080/", "http://example.com:8080"},
+		// trailing slash and empty query
+		{"https://example.com/", "https://example.com"},
+		{"https://example.com/a/", "https://example.com/a"},
+		{"https://example.com/a?", "https://example.com/a"},
+		// query order, repeated keys included
+		{"https://example.com/?b=2&a=1", "https://example.com?a=1&b=2"},
+		{"https://example.com/a?a=1&a=0", "https://example.com/a?a=0&a=1"},
+		// dot segments, unneeded escapes and trailing host dots
+		{"https://example.com/a/./b/../c", "https://example.com/a/c"},
+		{"https://example.com/%7Euser", "https://example.com/~user"},
+		{"https://example.com/a%2fb", "https://example.com/a/b"},
+		{"https://example.com./a", "https://example.com/a"},
+		// duplicate slashes, www and fragments are left as written
+		{"https://example.com/a//b", "https://example.com/a//b"},
+		{"https://www.example.com/", "https://www.example.com"},
+		{"https://example.com/a#frag", "https://example.com/a#frag"},
+	}
+
+	for _, tt := range tests {
+		got, err := Normalize(tt.url)
+		if err != nil {
+			t.Errorf("Normalize(%q): %v", tt.url, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestHashSameDestination(t *testing.T) {
+	tests := []struct {
+		a, b string
+		same bool
+	}{
+		{"https://example.com/a", "HTTPS://EXAMPLE.com:443/a/", true},
+		{"https://example.com/?a=1&b=2", "https://example.com?b=2&a=1", true},
+		{"https://example.com/a", "http://example.com/a", false},
+		{"https://example.com/a", "https://example.com/A", false},
+		{"https://example.com/a", "https://example.com:8443/a", false},
+		{"https://example.com/a/b", "https://example.com/a//b", false},
+		{"https://example.com/a", "https://www.example.com/a", false},
+	}
+
+	for _, tt := range tests {
+		a, err := Hash(tt.a)
+		if err != nil {
+			t.Fatalf("Hash(%q): %v", tt.a, err)
+		}
+		b, err := Hash(tt.b)
+		if err != nil {
+			t.Fatalf("Hash(%q): %v", tt.b, err)
+		}
+		if (a == b) != tt.same {
+			t.Errorf("Hash(%q) == Hash(%q) is %v, want %v", tt.a, tt.b, a == b, tt.same)
+		}
+	}
+}
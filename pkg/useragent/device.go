@@ -0,0 +1,50 @@
+package useragent
+
+import "strings"
+
+// Device classes
+const (
+	DeviceDesktop = "desktop"
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceBot     = "bot"
+	DeviceUnknown = "unknown"
+)
+
+var (
+	botMarkers    = []string{"bot", "crawler", "spider", "slurp", "facebookexternalhit", "preview", "curl", "wget", "python-requests", "go-http-client"}
+	tabletMarkers = []string{"ipad", "tablet", "kindle", "silk", "playbook"}
+	mobileMarkers = []string{"mobi", "iphone", "ipod", "android", "blackberry", "windows phone", "opera mini"}
+)
+
+// Device classifies the device a User-Agent header comes from
+func Device(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	if ua == "" {
+		return DeviceUnknown
+	}
+
+	switch {
+	case containsAny(ua, botMarkers):
+		return DeviceBot
+	case containsAny(ua, tabletMarkers), strings.Contains(ua, "android") && !strings.Contains(ua, "mobile"):
+		return DeviceTablet
+	case containsAny(ua, mobileMarkers):
+		return DeviceMobile
+	case strings.Contains(ua, "mozilla") || strings.Contains(ua, "opera"):
+		return DeviceDesktop
+	}
+
+	return DeviceUnknown
+}
+
+// containsAny reports whether s contains one of the markers
+func containsAny(s string, markers []string) bool {
+	for _, marker := range markers {
+		if strings.Contains(s, marker) {
+			return true
+		}
+	}
+
+	return false
+}
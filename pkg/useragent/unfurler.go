@@ -0,0 +1,16 @@
+package useragent
+
+import "strings"
+
+// unfurlerMarkers identify the bots of chat and social apps fetching a link to
+// render its preview card
+var unfurlerMarkers = []string{
+	"slackbot-linkexpanding", "slack-imgproxy", "twitterbot", "facebookexternalhit", "facebot",
+	"linkedinbot", "discordbot", "telegrambot", "whatsapp", "skypeuripreview", "redditbot",
+	"embedly", "iframely", "pinterestbot", "vkshare", "mastodon", "microsoft teams", "bitlybot",
+}
+
+// IsUnfurler reports whether a User-Agent header comes from a link preview bot
+func IsUnfurler(userAgent string) bool {
+	return containsAny(strings.ToLower(userAgent), unfurlerMarkers)
+}
@@ -0,0 +1,20 @@
+package webhook
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Backoff returns the delay before retrying after the given number of failed
+// attempts, doubling from base up to max with up to 10% of random jitter
+func Backoff(attempts int, base, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempts && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+
+	return delay + time.Duration(rand.Int63n(int64(delay)/10+1))
+}
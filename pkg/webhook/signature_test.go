@@ -0,0 +1,58 @@
+package webhook
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testSecret  = "whsec_test"
+	testPayload = `{"event":"link.created","id":42}`
+)
+
+func TestSignKnownVector(t *testing.T) {
+	got := Sign(testSecret, time.Unix(1700000000, 0), []byte(testPayload))
+	want := "t=1700000000,v1=a98ddeb7bc22d1c954941ac295c5e83c3a9a38f3ee3a3b337eecce7ea72d5fe2"
+	if got != want {
+		t.Errorf("Sign = %q, want %q", got, want)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Now()
+	header := Sign(testSecret, now, []byte(testPayload))
+
+	tests := []struct {
+		name      string
+		secret    string
+		header    string
+		payload   string
+		tolerance time.Duration
+		wantErr   string
+	}{
+		{"valid", testSecret, header, testPayload, DefaultTolerance, ""},
+		{"spaced header", testSecret, strings.ReplaceAll(header, ",", ", "), testPayload, DefaultTolerance, ""},
+		{"tampered body", testSecret, header, `{"event":"link.created","id":43}`, DefaultTolerance, "mismatch"},
+		{"appended body", testSecret, header, testPayload + " ", DefaultTolerance, "mismatch"},
+		{"empty body", testSecret, header, "", DefaultTolerance, "mismatch"},
+		{"wrong secret", "whsec_other", header, testPayload, DefaultTolerance, "mismatch"},
+		{"altered timestamp", testSecret, strings.Replace(header, "t=", "t=1", 1), testPayload, 0, "mismatch"},
+		{"within tolerance", testSecret, Sign(testSecret, now.Add(-4*time.Minute), []byte(testPayload)), testPayload, DefaultTolerance, ""},
+		{"expired", testSecret, Sign(testSecret, now.Add(-6*time.Minute), []byte(testPayload)), testPayload, DefaultTolerance, "expired"},
+		{"no tolerance", testSecret, Sign(testSecret, now.Add(-24*time.Hour), []byte(testPayload)), testPayload, 0, ""},
+		{"missing signature", testSecret, "t=1700000000", testPayload, DefaultTolerance, "malformed"},
+		{"missing timestamp", testSecret, "v1=abcdef", testPayload, DefaultTolerance, "malformed"},
+		{"invalid timestamp", testSecret, "t=yesterday,v1=abcdef", testPayload, DefaultTolerance, "malformed"},
+	}
+
+	for _, tt := range tests {
+		err := Verify(tt.secret, tt.header, []byte(tt.payload), tt.tolerance)
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("%s: Verify = %v, want nil", tt.name, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("%s: Verify = %v, want an error containing %q", tt.name, err, tt.wantErr)
+		}
+	}
+}